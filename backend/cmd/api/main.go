@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"time"
+	_ "time/tzdata" // schedules are evaluated in America/Los_Angeles
 
 	"theater-showtimes/internal/api"
+	"theater-showtimes/internal/config"
+	"theater-showtimes/internal/scheduler"
 	"theater-showtimes/internal/scrapers"
 	"theater-showtimes/internal/scrapers/clinton_street_theater"
 	"theater-showtimes/internal/scrapers/example_theater"
@@ -15,38 +20,83 @@ import (
 )
 
 func main() {
+	configPath := flag.String("config", "", "YAML config file (default "+config.DefaultPath+" if present); environment variables override it")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
 	// Initialize storage
-	store, err := storage.NewStorage("./data")
+	store, err := storage.Open(cfg.Storage.Backend, cfg.Storage.Path)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
-	// Initialize TMDB client (7-day cache)
-	tmdbClient := tmdb.NewClient(168 * time.Hour)
+	// Serve reads from an indexed in-memory copy that reloads when the
+	// scraper CLI changes the data on disk
+	cached := storage.NewCachedStore(store, 2*time.Second)
 
-	// Initialize scraper registry and register all scrapers
-	registry := scrapers.NewRegistry()
-	registry.Register(clinton_street_theater.NewScraper())
-	registry.Register(example_theater.NewScraper())
-	registry.Register(local_cinema.NewScraper())
+	// Initialize TMDB client
+	tmdbClient := tmdb.NewClient(cfg.TMDB.ClientConfig())
+	if tmdbClient.Enabled() {
+		log.Printf("TMDB lookups enabled (%s)", tmdbClient.AuthMethod())
+	} else {
+		log.Printf("TMDB disabled: set TMDB_API_KEY or TMDB_ACCESS_TOKEN to add movie details")
+	}
 
-	// Initialize theaters in storage
-	theaters := []interface{}{}
-	for _, scraper := range registry.GetAll() {
-		theaters = append(theaters, scraper.GetTheaterInfo())
+	// Initialize scraper registry and register the enabled scrapers
+	available := []scrapers.Scraper{
+		clinton_street_theater.NewScraper(),
+		example_theater.NewScraper(),
+		local_cinema.NewScraper(),
+	}
+	var ids []string
+	for _, scraper := range available {
+		ids = append(ids, scraper.GetID())
+	}
+	if err := cfg.CheckTheaters(ids); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	registry := scrapers.NewRegistry()
+	rateLimits := make(map[string]time.Duration)
+	for _, scraper := range available {
+		if !cfg.Enabled(scraper.GetID()) {
+			continue
+		}
+		registry.Register(scraper)
+		if limit := cfg.RateLimit(scraper.GetID()); limit > 0 {
+			rateLimits[scraper.GetID()] = limit
+		}
 	}
 
 	// Initialize API handler
-	handler := api.NewHandler(store, registry, tmdbClient)
+	handler := api.NewHandler(cached, registry, tmdbClient)
+	handler.SetScrapeConcurrency(cfg.Scraper.Concurrency)
+	handler.SetRateLimits(rateLimits)
+
+	// Scrape every theater on its schedule, unless disabled
+	if cfg.Scheduler.Enabled {
+		sched := scheduler.New(handler.Jobs(), store, cfg.Scheduler.Jitter, nil)
+		for _, id := range registry.GetIDs() {
+			if err := sched.Add(id, cfg.Schedule(id)); err != nil {
+				log.Fatalf("Failed to schedule %s: %v", id, err)
+			}
+		}
+		sched.Start(context.Background())
+		handler.SetScheduler(sched)
+	}
 
 	// Setup and start server
-	router := api.SetupRouter(handler)
+	router := api.SetupRouter(handler, cfg.Server.CORSOrigins)
 
-	port := ":8080"
-	fmt.Printf("Starting server on http://localhost%s\n", port)
+	fmt.Printf("Starting server on %s\n", cfg.Server.Address)
 	fmt.Printf("Available scrapers: %v\n", registry.GetIDs())
 	
-	if err := router.Run(port); err != nil {
+	if err := router.Run(cfg.Server.Address); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -1,14 +1,18 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
+	"theater-showtimes/internal/config"
 	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/pipeline"
 	"theater-showtimes/internal/scrapers"
 	"theater-showtimes/internal/scrapers/clinton_street_theater"
 	"theater-showtimes/internal/scrapers/example_theater"
@@ -18,31 +22,84 @@ import (
 )
 
 func main() {
+	timeout := flag.Duration("timeout", 10*time.Minute, "abort scraping after this long")
+	days := flag.Int("days", 0, "only keep showtimes within this many days from today (0 = scraper default)")
+	concurrency := flag.Int("concurrency", 0, "scrape up to this many theaters at once (default from config)")
+	record := flag.String("record", "", "save every fetched page as a replay fixture under this directory")
+	replay := flag.String("replay", "", "fetch pages from fixtures recorded under this directory instead of the network")
+	configPath := flag.String("config", "", "YAML config file (default "+config.DefaultPath+" if present); environment variables override it")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *concurrency <= 0 {
+		*concurrency = cfg.Scraper.Concurrency
+	}
+
+	if *record != "" && *replay != "" {
+		log.Fatal("-record and -replay cannot be combined")
+	}
+
+	// Cancel on Ctrl-C or when the timeout expires
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	opts := scrapers.ScrapeOptions{}
+	if *days > 0 {
+		opts.From = time.Now()
+		opts.To = opts.From.AddDate(0, 0, *days)
+	}
+
+	switch {
+	case *record != "":
+		opts.Transport = &scrapers.RecordingTransport{Dir: *record}
+	case *replay != "":
+		fixtures := scrapers.NewFixtureServer(*replay)
+		defer fixtures.Close()
+		opts.Transport = fixtures.Transport()
+		opts.NoDelay = true
+	}
+
 	// Initialize scraper registry
 	registry := scrapers.NewRegistry()
 	registry.Register(clinton_street_theater.NewScraper())
 	registry.Register(example_theater.NewScraper())
 	registry.Register(local_cinema.NewScraper())
+	if err := cfg.CheckTheaters(registry.GetIDs()); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
 
 	// Initialize storage
-	store, err := storage.NewStorage("./data")
+	store, err := storage.Open(cfg.Storage.Backend, cfg.Storage.Path)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
 	// Initialize TMDB client
-	tmdbClient := tmdb.NewClient(168 * time.Hour)
+	tmdbClient := tmdb.NewClient(cfg.TMDB.ClientConfig())
+	if tmdbClient.Enabled() {
+		log.Printf("TMDB lookups enabled (%s)", tmdbClient.AuthMethod())
+	} else {
+		log.Printf("TMDB disabled: set TMDB_API_KEY or TMDB_ACCESS_TOKEN to add movie details")
+	}
 
 	// Parse command line arguments
-	args := os.Args[1:]
+	args := flag.Args()
 	
 	var scrapersToRun []scrapers.Scraper
 	
 	if len(args) == 0 {
-		// Run all scrapers
-		fmt.Println("No scrapers specified, running all...")
+		// Run every scraper the config leaves enabled
+		fmt.Println("No scrapers specified, running all enabled...")
 		for _, scraper := range registry.GetAll() {
-			scrapersToRun = append(scrapersToRun, scraper)
+			if cfg.Enabled(scraper.GetID()) {
+				scrapersToRun = append(scrapersToRun, scraper)
+			}
 		}
 	} else {
 		// Run specified scrapers
@@ -62,75 +119,69 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Run scrapers
+	// Run the ingestion pipeline
 	fmt.Printf("Running %d scraper(s)...\n", len(scrapersToRun))
-	
-	allShowtimes := []models.Showtime{}
-	allMovies := make(map[string]*models.Movie)
-	
+
+	ingest := pipeline.New(store, tmdbClient, nil)
+	ingest.SetConcurrency(*concurrency)
+	rateLimits := make(map[string]time.Duration)
 	for _, scraper := range scrapersToRun {
-		fmt.Printf("\n=== Scraping %s ===\n", scraper.GetTheaterInfo().Name)
-		
-		showtimes, err := scraper.Scrape()
-		if err != nil {
-			log.Printf("Error scraping %s: %v", scraper.GetID(), err)
+		if limit := cfg.RateLimit(scraper.GetID()); limit > 0 {
+			rateLimits[scraper.GetID()] = limit
+		}
+	}
+	ingest.SetRateLimits(rateLimits)
+	report := ingest.Run(ctx, scrapersToRun, opts)
+
+	for _, result := range report.Results {
+		fmt.Printf("\n=== %s ===\n", result.Theater.Name)
+		if result.Metadata.Retries > 0 {
+			fmt.Printf("Requests: %d (%d retries)\n", result.Metadata.Attempts, result.Metadata.Retries)
+		}
+
+		if result.Err != nil {
+			fmt.Printf("Failed: %v\n", result.Err)
 			continue
 		}
-		
-		fmt.Printf("Found %d showtimes\n", len(showtimes))
-		
-		// Enrich with TMDB data
-		if len(showtimes) > 0 {
-			fmt.Println("\nEnriching with TMDB data...")
-			enriched, movieData := tmdbClient.EnrichShowtimes(showtimes)
-			showtimes = enriched
-			
-			// Merge movie data
-			for title, movie := range movieData {
-				if movie != nil {
-					allMovies[title] = movie
-				}
-			}
-			
-			// Display scraped showtimes with TMDB data
+
+		fmt.Printf("Found %d showtimes\n", len(result.Showtimes))
+		if result.Metadata.Status == models.ScrapePartial {
+			fmt.Printf("Partial: %d page(s) failed: %s\n", len(result.Metadata.FailedURLs), strings.Join(result.Metadata.FailedURLs, ", "))
+		}
+		if result.Metadata.Skipped > 0 {
+			fmt.Printf("Skipped %d unparsable listing(s)\n", result.Metadata.Skipped)
+		}
+
+		// Display scraped showtimes with TMDB data
+		if len(result.Showtimes) > 0 {
 			fmt.Println("\nShowtimes found:")
-			for _, st := range showtimes {
-				movie := movieData[st.MovieTitle]
-				if movie != nil {
-					fmt.Printf("  • %s (%s) - %s @ %s\n", st.MovieTitle, movie.ReleaseDate[:4], st.Date, st.Time)
-					fmt.Printf("    TMDB ID: %d, Rating: %.1f/10, Poster: %s\n", movie.TMDBID, movie.TMDBRating, movie.PosterPath)
-				} else {
-					fmt.Printf("  • %s - %s @ %s (No TMDB data)\n", st.MovieTitle, st.Date, st.Time)
-				}
+		}
+		for _, st := range result.Showtimes {
+			if st.TimeUnknown {
+				st.Time = "time unknown"
+			}
+			movie := result.Movies[st.MovieTitle]
+			if movie != nil {
+				fmt.Printf("  • %s (%s) - %s @ %s\n", st.MovieTitle, releaseYear(movie), st.Date, st.Time)
+				fmt.Printf("    TMDB ID: %d, Rating: %.1f/10, Poster: %s\n", movie.TMDBID, movie.TMDBRating, movie.PosterPath)
+			} else {
+				fmt.Printf("  • %s - %s @ %s (No TMDB data)\n", st.MovieTitle, st.Date, st.Time)
 			}
 		}
-		
-		allShowtimes = append(allShowtimes, showtimes...)
 	}
 
-	// Save results
+	// Summary
 	fmt.Printf("\n=== Summary ===\n")
-	fmt.Printf("Total showtimes: %d\n", len(allShowtimes))
-	fmt.Printf("Unique movies: %d\n", len(allMovies))
-	
-	// Save to JSON files
-	if len(allShowtimes) > 0 {
-		showtimesJSON, _ := json.MarshalIndent(allShowtimes, "", "  ")
-		if err := os.WriteFile("./data/showtimes.json", showtimesJSON, 0644); err != nil {
-			log.Printf("Failed to save showtimes: %v", err)
-		} else {
-			fmt.Println("\n✓ Saved showtimes to ./data/showtimes.json")
-		}
-	}
-	
-	if len(allMovies) > 0 {
-		moviesJSON, _ := json.MarshalIndent(allMovies, "", "  ")
-		if err := os.WriteFile("./data/movies.json", moviesJSON, 0644); err != nil {
-			log.Printf("Failed to save movies: %v", err)
-		} else {
-			fmt.Println("✓ Saved movie data to ./data/movies.json")
-		}
+	fmt.Printf("Total showtimes: %d\n", report.TotalShowtimes)
+	fmt.Printf("Unique movies: %d\n", report.UniqueMovies)
+	fmt.Printf("Theaters succeeded: %d, failed: %d\n", report.Succeeded, report.Failed)
+	fmt.Printf("Duration: %s\n", report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond))
+}
+
+// releaseYear returns the year part of a movie's release date, if known
+func releaseYear(movie *models.Movie) string {
+	if len(movie.ReleaseDate) < 4 {
+		return "unknown"
 	}
-	
-	_ = store // Storage interface for future use
+	return movie.ReleaseDate[:4]
 }
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"theater-showtimes/internal/events"
+	"theater-showtimes/internal/jobs"
+)
+
+// sseHeartbeat is how often an idle event stream sends a keep-alive comment
+const sseHeartbeat = 15 * time.Second
+
+// StreamScrapeEvents streams scrape progress as Server-Sent Events. Each
+// event is named after its type and carries the event as JSON. With
+// ?job_id= only that job's events are sent and the stream ends when the
+// job finishes. An unknown job is a 404, and a job that has already
+// finished gets a single job_finished event.
+func (h *Handler) StreamScrapeEvents(c *gin.Context) {
+	jobID := c.Query("job_id")
+
+	// Subscribe before looking the job up, so a job finishing in between
+	// still delivers its job_finished event
+	stream, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	var finished *jobs.Job
+	if jobID != "" {
+		job, err := h.jobs.Get(jobID)
+		if errors.Is(err, jobs.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if !job.Active() {
+			finished = &job
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// Let the client know the stream is open before the first event
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	if finished != nil {
+		event := events.Event{Type: events.JobFinished, JobID: finished.ID, Status: string(finished.Status), Data: finished.Summary}
+		if finished.FinishedAt != nil {
+			event.Time = *finished.FinishedAt
+		}
+		c.SSEvent(string(event.Type), event)
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			io.WriteString(w, ": keep-alive\n\n")
+			return true
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			if jobID != "" && event.JobID != jobID {
+				return true
+			}
+			c.SSEvent(string(event.Type), event)
+			return jobID == "" || event.Type != events.JobFinished
+		}
+	})
+}
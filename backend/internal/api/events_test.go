@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/events"
+)
+
+func TestStreamScrapeEvents(t *testing.T) {
+	handler := newScrapeHandler(t)
+	server := httptest.NewServer(SetupRouter(handler, testOrigins))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/scrape/events")
+	if err != nil {
+		t.Fatalf("GET events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// Reading the first comment guarantees the subscription exists
+	lines := bufio.NewScanner(resp.Body)
+	if !lines.Scan() || lines.Text() != ": connected" {
+		t.Fatalf("first line = %q, want connected comment", lines.Text())
+	}
+
+	post, err := http.Post(server.URL+"/api/scrape", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST scrape error = %v", err)
+	}
+	post.Body.Close()
+
+	var got []events.Type
+	timeout := time.AfterFunc(5*time.Second, func() { resp.Body.Close() })
+	defer timeout.Stop()
+
+	for lines.Scan() {
+		data, ok := strings.CutPrefix(lines.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event events.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("failed to decode event %q: %v", data, err)
+		}
+		if event.JobID == "" {
+			t.Errorf("%s event has no job ID", event.Type)
+		}
+		got = append(got, event.Type)
+		if event.Type == events.JobFinished {
+			break
+		}
+	}
+
+	want := []events.Type{
+		events.JobStarted,
+		events.TheaterStarted,
+		events.ShowtimeExtracted,
+		events.TheaterFinished,
+		events.JobFinished,
+	}
+	if strings.Join(typeNames(got), ",") != strings.Join(typeNames(want), ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestStreamScrapeEvents_JobID(t *testing.T) {
+	handler := newScrapeHandler(t)
+	server := httptest.NewServer(SetupRouter(handler, testOrigins))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/scrape/events?job_id=missing")
+	if err != nil {
+		t.Fatalf("GET events error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown job status = %d, want 404", resp.StatusCode)
+	}
+
+	job, _, err := handler.jobs.Submit(nil)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := handler.jobs.Wait(context.Background(), job.ID); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err = client.Get(server.URL + "/api/scrape/events?job_id=" + job.ID)
+	if err != nil {
+		t.Fatalf("GET events error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The stream must end on its own after the one event
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading finished job's stream error = %v", err)
+	}
+	if got := strings.Count(string(body), "event:"); got != 1 || !strings.Contains(string(body), "event:job_finished") {
+		t.Errorf("finished job's stream = %q, want a single job_finished event", body)
+	}
+}
+
+func typeNames(types []events.Type) []string {
+	names := make([]string, len(types))
+	for i, typ := range types {
+		names[i] = string(typ)
+	}
+	return names
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/storage"
+)
+
+// ShowtimeFilter holds the parsed /api/showtimes query parameters
+type ShowtimeFilter struct {
+	Dates      storage.DateRange // date, or from/to (YYYY-MM-DD, inclusive)
+	TimeFrom   string            // time_from (HH:MM, inclusive)
+	TimeTo     string            // time_to (HH:MM, inclusive), wraps past midnight when before TimeFrom
+	StartFrom  time.Time         // start_from (RFC 3339, inclusive)
+	StartTo    time.Time         // start_to (RFC 3339, inclusive)
+	Theaters   []string          // theater, repeatable or comma-separated
+	Formats    []string          // format, case-insensitive
+	Genres     []string          // genre, matches any, case-insensitive
+	TMDBID     int               // tmdb_id
+	MinRating  float64           // min_rating, TMDB vote average 0-10
+	MaxRuntime int               // max_runtime, minutes
+	Title      string            // movie, case-insensitive substring
+}
+
+// parseShowtimeFilter reads and validates the filter query parameters.
+// The returned error is meant for the client and should be sent as a 400.
+func parseShowtimeFilter(c *gin.Context) (ShowtimeFilter, error) {
+	var f ShowtimeFilter
+
+	date := c.Query("date")
+	from := c.Query("from")
+	to := c.Query("to")
+	if date != "" && (from != "" || to != "") {
+		return f, fmt.Errorf("date cannot be combined with from/to")
+	}
+	if date != "" {
+		from, to = date, date
+	}
+	for name, value := range map[string]string{"date/from": from, "date/to": to} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return f, fmt.Errorf("%s must be a date in YYYY-MM-DD format, got %q", name, value)
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return f, fmt.Errorf("from (%s) must not be after to (%s)", from, to)
+	}
+	f.Dates = storage.DateRange{From: from, To: to}
+
+	var err error
+	if f.TimeFrom, err = parseClock(c.Query("time_from")); err != nil {
+		return f, fmt.Errorf("time_from %v", err)
+	}
+	if f.TimeTo, err = parseClock(c.Query("time_to")); err != nil {
+		return f, fmt.Errorf("time_to %v", err)
+	}
+	if f.StartFrom, err = parseInstant(c.Query("start_from")); err != nil {
+		return f, fmt.Errorf("start_from %v", err)
+	}
+	if f.StartTo, err = parseInstant(c.Query("start_to")); err != nil {
+		return f, fmt.Errorf("start_to %v", err)
+	}
+	if !f.StartFrom.IsZero() && !f.StartTo.IsZero() && f.StartFrom.After(f.StartTo) {
+		return f, fmt.Errorf("start_from must not be after start_to")
+	}
+
+	f.Theaters = listParam(c, "theater")
+	f.Formats = listParam(c, "format")
+	f.Genres = listParam(c, "genre")
+	f.Title = strings.TrimSpace(c.Query("movie"))
+
+	if value := c.Query("tmdb_id"); value != "" {
+		f.TMDBID, err = strconv.Atoi(value)
+		if err != nil || f.TMDBID <= 0 {
+			return f, fmt.Errorf("tmdb_id must be a positive integer, got %q", value)
+		}
+	}
+
+	if value := c.Query("min_rating"); value != "" {
+		f.MinRating, err = strconv.ParseFloat(value, 64)
+		if err != nil || f.MinRating < 0 || f.MinRating > 10 {
+			return f, fmt.Errorf("min_rating must be a number between 0 and 10, got %q", value)
+		}
+	}
+
+	if value := c.Query("max_runtime"); value != "" {
+		f.MaxRuntime, err = strconv.Atoi(value)
+		if err != nil || f.MaxRuntime <= 0 {
+			return f, fmt.Errorf("max_runtime must be a positive number of minutes, got %q", value)
+		}
+	}
+
+	return f, nil
+}
+
+// Query returns the indexed storage query that narrows the candidates
+// before the remaining filters are applied in memory
+func (f ShowtimeFilter) Query() storage.ShowtimeQuery {
+	query := storage.ShowtimeQuery{
+		Dates:  f.Dates,
+		TMDBID: f.TMDBID,
+	}
+	if query.Dates.From == "" && !f.StartFrom.IsZero() {
+		query.Dates.From = f.StartFrom.In(scrapers.Location).Format("2006-01-02")
+	}
+	if query.Dates.To == "" && !f.StartTo.IsZero() {
+		query.Dates.To = f.StartTo.In(scrapers.Location).Format("2006-01-02")
+	}
+	// Theaters stay out of the storage query, which matches IDs exactly,
+	// so that theater is case-insensitive however many are given
+	return query
+}
+
+// inTimeWindow reports whether the HH:MM clock falls between TimeFrom and
+// TimeTo. A TimeFrom after TimeTo is a late-night window that crosses
+// midnight, so 22:00 to 01:00 keeps 23:30 and 00:15 but not 12:00.
+func (f ShowtimeFilter) inTimeWindow(clock string) bool {
+	if f.TimeFrom != "" && f.TimeTo != "" && f.TimeFrom > f.TimeTo {
+		return clock >= f.TimeFrom || clock <= f.TimeTo
+	}
+	if f.TimeFrom != "" && clock < f.TimeFrom {
+		return false
+	}
+	if f.TimeTo != "" && clock > f.TimeTo {
+		return false
+	}
+	return true
+}
+
+// NeedsMovies reports whether filtering requires the TMDB movie data
+func (f ShowtimeFilter) NeedsMovies() bool {
+	return len(f.Genres) > 0 || f.MinRating > 0 || f.MaxRuntime > 0
+}
+
+// Apply returns the showtimes that pass every filter. movies maps TMDB IDs
+// to movie data and is only consulted for genre, rating and runtime.
+func (f ShowtimeFilter) Apply(showtimes []models.Showtime, movies map[int]models.Movie) []models.Showtime {
+	filtered := []models.Showtime{}
+	title := strings.ToLower(f.Title)
+
+	for _, st := range showtimes {
+		if (f.Dates.From != "" || f.Dates.To != "") && (st.Date == "" || !f.Dates.Contains(st.Date)) {
+			continue
+		}
+		if f.TimeFrom != "" || f.TimeTo != "" || !f.StartFrom.IsZero() || !f.StartTo.IsZero() {
+			start, known := startOf(st)
+			if !known {
+				continue
+			}
+			clock := start.In(scrapers.Location).Format("15:04")
+			if !f.inTimeWindow(clock) {
+				continue
+			}
+			if !f.StartFrom.IsZero() && start.Before(f.StartFrom) {
+				continue
+			}
+			if !f.StartTo.IsZero() && start.After(f.StartTo) {
+				continue
+			}
+		}
+		if len(f.Theaters) > 0 && !containsFold(f.Theaters, st.TheaterID) {
+			continue
+		}
+		if len(f.Formats) > 0 && !containsFold(f.Formats, st.Format) {
+			continue
+		}
+		if f.TMDBID != 0 && st.TMDBID != f.TMDBID {
+			continue
+		}
+		if title != "" && !strings.Contains(strings.ToLower(st.MovieTitle), title) {
+			continue
+		}
+
+		if f.NeedsMovies() {
+			movie, exists := movies[st.TMDBID]
+			if !exists || st.TMDBID == 0 {
+				continue
+			}
+			if len(f.Genres) > 0 && !anyFold(f.Genres, movie.Genres) {
+				continue
+			}
+			if f.MinRating > 0 && movie.TMDBRating < f.MinRating {
+				continue
+			}
+			if f.MaxRuntime > 0 && (movie.Runtime == 0 || movie.Runtime > f.MaxRuntime) {
+				continue
+			}
+		}
+
+		filtered = append(filtered, st)
+	}
+
+	return filtered
+}
+
+// parseClock validates an HH:MM time of day and normalizes it to two-digit hours
+func parseClock(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", fmt.Errorf("must be a time in HH:MM format, got %q", value)
+	}
+	return t.Format("15:04"), nil
+}
+
+// parseInstant validates an RFC 3339 timestamp such as 2026-03-04T18:00:00-08:00
+func parseInstant(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp such as 2026-03-04T18:00:00-08:00, got %q", value)
+	}
+	return t, nil
+}
+
+// startOf returns when st starts. Showtimes stored before start times were
+// recorded get one from their date and time; ok is false when it is unknown.
+func startOf(st models.Showtime) (start time.Time, ok bool) {
+	if st.StartsAt != nil {
+		return *st.StartsAt, true
+	}
+	if st.TimeUnknown {
+		return time.Time{}, false
+	}
+	start, err := scrapers.StartTime(st.Date, st.Time)
+	return start, err == nil
+}
+
+// listParam collects a repeatable, comma-separated query parameter
+func listParam(c *gin.Context, name string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// containsFold reports whether values contains target, ignoring case
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyFold reports whether any wanted value appears in have, ignoring case
+func anyFold(wanted, have []string) bool {
+	for _, value := range have {
+		if containsFold(wanted, value) {
+			return true
+		}
+	}
+	return false
+}
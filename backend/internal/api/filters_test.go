@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/storage"
+)
+
+// seedFilterData stores the movies and showtimes the filter and listing tests query
+func seedFilterData(t *testing.T, store storage.Store) {
+	t.Helper()
+
+	if err := store.SaveMovies([]models.Movie{
+		{TMDBID: 348, Title: "Alien", Genres: []string{"Horror", "Science Fiction"}, Runtime: 117, TMDBRating: 8.1},
+		{TMDBID: 949, Title: "Heat", Genres: []string{"Crime", "Drama"}, Runtime: 170, TMDBRating: 7.9},
+		{TMDBID: 620, Title: "Ghostbusters", Genres: []string{"Comedy"}, Runtime: 105, TMDBRating: 7.4},
+	}); err != nil {
+		t.Fatalf("SaveMovies() error = %v", err)
+	}
+	if err := store.SaveShowtimes([]models.Showtime{
+		{ID: "1", TheaterID: "cst", MovieTitle: "Alien", TMDBID: 348, Date: "2026-03-01", Time: "19:00", Format: "35mm"},
+		{ID: "2", TheaterID: "cst", MovieTitle: "Heat", TMDBID: 949, Date: "2026-03-02", Time: "21:30", Format: "Digital"},
+		{ID: "3", TheaterID: "academy", MovieTitle: "Alien", TMDBID: 348, Date: "2026-03-03", Time: "13:00", Format: "Digital"},
+		{ID: "4", TheaterID: "hollywood", MovieTitle: "Ghostbusters", TMDBID: 620, Date: "2026-03-04", Time: "16:15", Format: "70mm"},
+		{ID: "5", TheaterID: "hollywood", MovieTitle: "Unknown Short Films", Date: "2026-03-04"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+}
+
+func TestGetShowtimes_Filters(t *testing.T) {
+	router := newTestRouter(t, seedFilterData)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"1", "2", "3", "4", "5"}},
+		{"date=2026-03-02", []string{"2"}},
+		{"from=2026-03-02&to=2026-03-03", []string{"2", "3"}},
+		{"from=2026-03-03", []string{"3", "4", "5"}},
+		{"time_from=14:00&time_to=20:00", []string{"1", "4"}},
+		{"time_from=9:00&time_to=13:00", []string{"3"}},
+		{"time_from=21:00&time_to=14:00", []string{"2", "3"}},
+		{"time_from=22:00&time_to=01:00", []string{}},
+		{"start_from=2026-03-02T00:00:00-08:00&start_to=2026-03-03T13:00:00-08:00", []string{"2", "3"}},
+		{"start_from=2026-03-04T00:00:00Z", []string{"4"}},
+		{"theater=cst&theater=academy", []string{"1", "2", "3"}},
+		{"theater=cst,hollywood", []string{"1", "2", "4", "5"}},
+		{"theater=CST", []string{"1", "2"}},
+		{"theater=CST,Academy", []string{"1", "2", "3"}},
+		{"format=digital", []string{"2", "3"}},
+		{"genre=comedy,drama", []string{"2", "4"}},
+		{"tmdb_id=348", []string{"1", "3"}},
+		{"min_rating=8", []string{"1", "3"}},
+		{"max_runtime=120", []string{"1", "3", "4"}},
+		{"movie=ali", []string{"1", "3"}},
+		{"movie=ALIEN&theater=academy", []string{"3"}},
+		{"genre=western", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []models.Showtime
+			if code := get(t, router, "/api/showtimes?"+tt.query, &got); code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", code)
+			}
+
+			ids := make([]string, len(got))
+			for i, st := range got {
+				ids[i] = st.ID
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got IDs %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetShowtimes_InvalidFilters(t *testing.T) {
+	router := newTestRouter(t, nil)
+
+	queries := []string{
+		"date=03/01/2026",
+		"date=2026-03-01&from=2026-03-01",
+		"from=2026-03-05&to=2026-03-01",
+		"to=tomorrow",
+		"time_from=7pm",
+		"time_to=25:00",
+		"start_from=2026-03-04",
+		"start_from=2026-03-05T00:00:00Z&start_to=2026-03-04T00:00:00Z",
+		"tmdb_id=abc",
+		"tmdb_id=-1",
+		"min_rating=11",
+		"min_rating=high",
+		"max_runtime=0",
+	}
+
+	for _, query := range queries {
+		if code := get(t, router, "/api/showtimes?"+query, nil); code != http.StatusBadRequest {
+			t.Errorf("GET /api/showtimes?%s status = %d, want 400", query, code)
+		}
+	}
+}
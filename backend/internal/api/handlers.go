@@ -1,101 +1,225 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"theater-showtimes/internal/events"
+	"theater-showtimes/internal/health"
+	"theater-showtimes/internal/jobs"
 	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/pipeline"
+	"theater-showtimes/internal/scheduler"
 	"theater-showtimes/internal/scrapers"
 	"theater-showtimes/internal/storage"
 	"theater-showtimes/internal/tmdb"
 )
 
+// scrapeTimeout bounds how long a scrape job may run
+const scrapeTimeout = 5 * time.Minute
+
+// defaultHistoryLimit is how many scrape records /scrape/history returns by default
+const defaultHistoryLimit = 50
+
 // Handler contains all API handlers
 type Handler struct {
-	storage  *storage.Storage
-	registry *scrapers.Registry
-	tmdb     *tmdb.Client
+	storage   storage.Store
+	registry  *scrapers.Registry
+	tmdb      *tmdb.Client
+	pipeline  *pipeline.Pipeline
+	jobs      *jobs.Manager
+	events    *events.Bus
+	scheduler *scheduler.Scheduler
+
+	// now is the handler's clock, swapped out by tests
+	now func() time.Time
 }
 
 // NewHandler creates a new API handler
-func NewHandler(storage *storage.Storage, registry *scrapers.Registry, tmdb *tmdb.Client) *Handler {
-	return &Handler{
+func NewHandler(storage storage.Store, registry *scrapers.Registry, tmdb *tmdb.Client) *Handler {
+	h := &Handler{
 		storage:  storage,
 		registry: registry,
 		tmdb:     tmdb,
+		pipeline: pipeline.New(storage, tmdb, nil),
+		events:   events.NewBus(),
+		now:      time.Now,
 	}
+	h.jobs = jobs.NewManager(h.runScrape, h.events.Publish)
+	return h
+}
+
+// Jobs returns the manager that runs the handler's scrape jobs
+func (h *Handler) Jobs() *jobs.Manager {
+	return h.jobs
+}
+
+// SetScrapeConcurrency limits how many theaters a scrape job scrapes at once
+func (h *Handler) SetScrapeConcurrency(n int) {
+	h.pipeline.SetConcurrency(n)
+}
+
+// SetRateLimits replaces the request delay of the scrapers with the given IDs
+func (h *Handler) SetRateLimits(limits map[string]time.Duration) {
+	h.pipeline.SetRateLimits(limits)
+}
+
+// SetScheduler exposes s through GET /api/schedule
+func (h *Handler) SetScheduler(s *scheduler.Scheduler) {
+	h.scheduler = s
 }
 
 // GetTheaters returns all theaters
 func (h *Handler) GetTheaters(c *gin.Context) {
+	opts, err := parseListOptions(c, theaterSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	theaters, err := h.storage.LoadTheaters()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, theaters)
+	respondList(c, theaters, opts, theaterSortFields, theaterDefaultSort)
 }
 
 // GetShowtimes returns all showtimes with optional filters
 func (h *Handler) GetShowtimes(c *gin.Context) {
-	showtimes, err := h.storage.LoadShowtimes()
+	filter, err := parseShowtimeFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := parseListOptions(c, showtimeSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Apply filters
-	date := c.Query("date")
-	theater := c.Query("theater")
-	movie := c.Query("movie")
+	showtimes, err := h.storage.QueryShowtimes(filter.Query())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	filtered := h.filterShowtimes(showtimes, date, theater, movie)
+	var movies map[int]models.Movie
+	if filter.NeedsMovies() {
+		loaded, err := h.storage.LoadMovies()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		movies = make(map[int]models.Movie, len(loaded))
+		for _, movie := range loaded {
+			movies[movie.TMDBID] = movie
+		}
+	}
 
-	c.JSON(http.StatusOK, filtered)
+	respondList(c, filter.Apply(showtimes, movies), opts, showtimeSortFields, showtimeDefaultSort)
 }
 
 // GetTheaterShowtimes returns showtimes for a specific theater
 func (h *Handler) GetTheaterShowtimes(c *gin.Context) {
 	theaterID := c.Param("theater")
 
-	showtimes, err := h.storage.LoadShowtimes()
+	opts, err := parseListOptions(c, showtimeSortFields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Filter by theater
-	var filtered []models.Showtime
-	for _, st := range showtimes {
-		if st.TheaterID == theaterID {
-			filtered = append(filtered, st)
-		}
+	showtimes, err := h.storage.QueryShowtimes(storage.ShowtimeQuery{TheaterID: theaterID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, filtered)
+	respondList(c, showtimes, opts, showtimeSortFields, showtimeDefaultSort)
 }
 
 // GetMovies returns all unique movies
 func (h *Handler) GetMovies(c *gin.Context) {
+	opts, err := parseListOptions(c, movieSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	movies, err := h.storage.LoadMovies()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, movies)
+	respondList(c, movies, opts, movieSortFields, movieDefaultSort)
 }
 
-// GetMovieDetails returns details for a specific movie
+// GetMovieDetails returns a movie by TMDB ID with its upcoming showtimes
+// grouped by theater and date. Movies we have not stored yet are looked up
+// live on TMDB (through the client's cache).
 func (h *Handler) GetMovieDetails(c *gin.Context) {
-	_ = c.Param("id") // movieID - TODO: Convert to int and fetch from TMDB
+	tmdbID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || tmdbID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "movie id must be a positive TMDB ID"})
+		return
+	}
 
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "Not yet implemented"})
+	movie, err := h.storage.GetMovie(tmdbID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if movie == nil {
+		movie, err = h.tmdb.GetMovieDetails(tmdbID)
+		if errors.Is(err, tmdb.ErrDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "TMDB is disabled; only stored movies are available"})
+			return
+		}
+		if errors.Is(err, tmdb.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("movie %d not found", tmdbID)})
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to look up movie %d on TMDB: %v", tmdbID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to look up movie on TMDB"})
+			return
+		}
+	}
+
+	showtimes, err := h.storage.QueryShowtimes(storage.ShowtimeQuery{
+		TMDBID: tmdbID,
+		// Showtime dates are local to the theaters, not the server
+		Dates: storage.DateRange{From: h.now().In(scrapers.Location).Format("2006-01-02")},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	theaters, err := h.storage.LoadTheaters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MovieDetails{
+		Movie:    *movie,
+		Theaters: groupShowtimes(showtimes, theaters),
+	})
 }
 
-// TriggerScrape triggers the scraper on-demand
+// TriggerScrape starts a background scrape job and returns it for polling.
+// A request for the same theaters as a running job returns that job instead.
 func (h *Handler) TriggerScrape(c *gin.Context) {
 	var request struct {
 		TheaterIDs []string `json:"theater_ids,omitempty"`
@@ -107,89 +231,180 @@ func (h *Handler) TriggerScrape(c *gin.Context) {
 	}
 
 	// If no specific theaters requested, scrape all
-	scrapersToRun := h.registry.GetAll()
-	if len(request.TheaterIDs) > 0 {
-		scrapersToRun = make(map[string]scrapers.Scraper)
-		for _, id := range request.TheaterIDs {
-			if scraper, exists := h.registry.Get(id); exists {
-				scrapersToRun[id] = scraper
-			}
+	theaterIDs := request.TheaterIDs
+	if len(theaterIDs) == 0 {
+		theaterIDs = h.registry.GetIDs()
+	}
+	for _, id := range theaterIDs {
+		if _, exists := h.registry.Get(id); !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown theater %q", id)})
+			return
 		}
 	}
 
-	// Run scrapers
-	results := h.runScrapers(scrapersToRun)
+	job, coalesced, err := h.jobs.Submit(theaterIDs)
+	var conflict *jobs.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error(), "job_id": conflict.JobID})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/api/scrape/jobs/"+job.ID)
+	if coalesced {
+		c.JSON(http.StatusOK, job)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Scraping completed",
-		"results": results,
-	})
+// ListScrapeJobs returns running and recently finished scrape jobs, newest first
+func (h *Handler) ListScrapeJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.jobs.List())
 }
 
-// Health returns health status
-func (h *Handler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-		"time":   time.Now(),
-	})
+// GetScrapeJob returns a scrape job's status and per-theater results so far
+func (h *Handler) GetScrapeJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
 }
 
-// GetLastUpdated returns the last scrape timestamp
-func (h *Handler) GetLastUpdated(c *gin.Context) {
-	lastUpdate, err := h.storage.GetLastUpdate()
+// CancelScrapeJob stops a running scrape job
+func (h *Handler) CancelScrapeJob(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, jobs.ErrFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "job": job})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetScrapeHistory returns past scrape records, newest first, for every
+// theater or the one given by ?theater=. ?limit= caps the number returned.
+func (h *Handler) GetScrapeHistory(c *gin.Context) {
+	limit := defaultHistoryLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxPageSize)})
+			return
+		}
+		limit = n
+	}
+
+	history, err := h.storage.LoadMetadata(c.Query("theater"), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"last_updated": lastUpdate,
-	})
+	c.JSON(http.StatusOK, history)
 }
 
-// Helper functions
-
-func (h *Handler) filterShowtimes(showtimes []models.Showtime, date, theater, movie string) []models.Showtime {
-	var filtered []models.Showtime
+// runScrape runs the pipeline for registered theaters; it is the jobs.RunFunc
+// behind every scrape job
+func (h *Handler) runScrape(ctx context.Context, jobID string, theaterIDs []string, progress func(pipeline.TheaterResult)) pipeline.Report {
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
 
-	for _, st := range showtimes {
-		if date != "" && st.Date != date {
-			continue
+	toRun := make([]scrapers.Scraper, 0, len(theaterIDs))
+	for _, id := range theaterIDs {
+		if scraper, exists := h.registry.Get(id); exists {
+			toRun = append(toRun, scraper)
 		}
-		if theater != "" && st.TheaterID != theater {
-			continue
+	}
+
+	opts := scrapers.ScrapeOptions{
+		Events: func(event events.Event) {
+			event.JobID = jobID
+			h.events.Publish(event)
+		},
+	}
+
+	return h.pipeline.RunWithProgress(ctx, toRun, opts, progress)
+}
+
+// GetSchedule returns each theater's scrape schedule with its last and next run
+func (h *Handler) GetSchedule(c *gin.Context) {
+	if h.scheduler == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "theaters": []scheduler.State{}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "theaters": h.scheduler.States()})
+}
+
+// GetScrapersHealth reports each registered scraper as healthy, degraded,
+// failing or unknown, based on its recent scrape history
+func (h *Handler) GetScrapersHealth(c *gin.Context) {
+	ids := h.registry.GetIDs()
+	sort.Strings(ids)
+
+	overall := health.StateHealthy
+	statuses := make([]health.Status, 0, len(ids))
+	for _, id := range ids {
+		history, err := h.storage.LoadMetadata(id, health.HistoryWindow)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-		if movie != "" && st.MovieTitle != movie {
-			continue
+
+		status := health.Assess(id, history)
+		if status.State == health.StateDegraded || status.State == health.StateFailing {
+			overall = health.StateDegraded
 		}
-		filtered = append(filtered, st)
+		statuses = append(statuses, status)
 	}
 
-	return filtered
+	c.JSON(http.StatusOK, gin.H{"status": overall, "scrapers": statuses})
 }
 
-func (h *Handler) runScrapers(scrapers map[string]scrapers.Scraper) []models.ScrapeMetadata {
-	results := []models.ScrapeMetadata{}
+// Health returns health status
+func (h *Handler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "healthy",
+		"time":   time.Now(),
+		"tmdb":   h.tmdb.AuthMethod(),
+	})
+}
 
-	for _, scraper := range scrapers {
-		metadata := models.ScrapeMetadata{
-			LastUpdated: time.Now(),
-			TheaterID:   scraper.GetID(),
-			Status:      "success",
-		}
+// GetLastUpdated returns the last scrape timestamp and each theater's most
+// recent scrape record, so partial and failed scrapes are visible
+func (h *Handler) GetLastUpdated(c *gin.Context) {
+	lastUpdate, err := h.storage.GetLastUpdate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := h.registry.GetIDs()
+	sort.Strings(ids)
 
-		showtimes, err := scraper.Scrape()
+	theaters := []models.ScrapeMetadata{}
+	for _, id := range ids {
+		latest, err := h.storage.LoadMetadata(id, 1)
 		if err != nil {
-			metadata.Status = "error"
-			metadata.ErrorMessage = err.Error()
-		} else {
-			metadata.ShowtimesScraped = len(showtimes)
-			// TODO: Save showtimes, enrich with TMDB data, etc.
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-
-		results = append(results, metadata)
-		h.storage.SaveMetadata(metadata)
+		theaters = append(theaters, latest...)
 	}
 
-	return results
+	c.JSON(http.StatusOK, gin.H{
+		"last_updated": lastUpdate,
+		"theaters":     theaters,
+	})
 }
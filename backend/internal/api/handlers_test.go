@@ -0,0 +1,401 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"theater-showtimes/internal/events"
+	"theater-showtimes/internal/health"
+	"theater-showtimes/internal/jobs"
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/pipeline"
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/storage"
+	"theater-showtimes/internal/tmdb"
+)
+
+// testOrigins are the CORS origins test routers allow
+var testOrigins = []string{"http://localhost:3000"}
+
+// newTestRouter builds the API over a temporary JSON store seeded by seed,
+// with TMDB disabled
+func newTestRouter(t *testing.T, seed func(t *testing.T, store storage.Store)) *gin.Engine {
+	t.Helper()
+	return newTMDBRouter(t, seed, tmdb.NewClient(tmdb.Config{CacheTTL: time.Hour}))
+}
+
+// newTMDBRouter builds the API over a temporary JSON store seeded by seed,
+// looking up movies with client
+func newTMDBRouter(t *testing.T, seed func(t *testing.T, store storage.Store), client *tmdb.Client) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if seed != nil {
+		seed(t, store)
+	}
+
+	handler := NewHandler(store, scrapers.NewRegistry(), client)
+	return SetupRouter(handler, testOrigins)
+}
+
+// get performs a GET request and decodes the JSON response into out
+func get(t *testing.T, router *gin.Engine, path string, out interface{}) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if out != nil && rec.Code == http.StatusOK {
+		decodeJSON(t, rec, out)
+	}
+	return rec.Code
+}
+
+// decodeJSON decodes a recorded JSON response body into out
+func decodeJSON(t *testing.T, rec *httptest.ResponseRecorder, out interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("failed to decode %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestGetMovieDetails_GroupsUpcomingShowtimes(t *testing.T) {
+	today := time.Now()
+	tomorrow := today.AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := today.AddDate(0, 0, -1).Format("2006-01-02")
+
+	router := newTestRouter(t, func(t *testing.T, store storage.Store) {
+		if err := store.SaveTheaters([]models.Theater{
+			{ID: "cst", Name: "Clinton Street Theater"},
+			{ID: "academy", Name: "Academy Theater"},
+		}); err != nil {
+			t.Fatalf("SaveTheaters() error = %v", err)
+		}
+		if err := store.SaveMovies([]models.Movie{{TMDBID: 348, Title: "Alien"}}); err != nil {
+			t.Fatalf("SaveMovies() error = %v", err)
+		}
+		if err := store.SaveShowtimes([]models.Showtime{
+			{ID: "1", TheaterID: "cst", TMDBID: 348, Date: tomorrow, Time: "21:00"},
+			{ID: "2", TheaterID: "cst", TMDBID: 348, Date: tomorrow, Time: "19:00"},
+			{ID: "3", TheaterID: "academy", TMDBID: 348, Date: tomorrow, Time: "18:00"},
+			{ID: "4", TheaterID: "cst", TMDBID: 348, Date: yesterday, Time: "19:00"},
+			{ID: "5", TheaterID: "cst", TMDBID: 949, Date: tomorrow, Time: "19:00"},
+		}); err != nil {
+			t.Fatalf("SaveShowtimes() error = %v", err)
+		}
+	})
+
+	var details MovieDetails
+	if code := get(t, router, "/api/movies/348", &details); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	if details.Title != "Alien" {
+		t.Errorf("Title = %q, want Alien", details.Title)
+	}
+	if len(details.Theaters) != 2 {
+		t.Fatalf("got %d theaters, want 2", len(details.Theaters))
+	}
+	if details.Theaters[0].Theater.ID != "academy" {
+		t.Errorf("first theater = %s, want academy (sorted by name)", details.Theaters[0].Theater.ID)
+	}
+
+	cst := details.Theaters[1]
+	if len(cst.Dates) != 1 || cst.Dates[0].Date != tomorrow {
+		t.Fatalf("cst dates = %+v, want only %s", cst.Dates, tomorrow)
+	}
+	if got := cst.Dates[0].Showtimes; len(got) != 2 || got[0].ID != "2" || got[1].ID != "1" {
+		t.Errorf("cst showtimes = %+v, want IDs 2 then 1", got)
+	}
+}
+
+func TestGetMovieDetails_UpcomingInTheaterZone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if err := store.SaveMovies([]models.Movie{{TMDBID: 348, Title: "Alien"}}); err != nil {
+		t.Fatalf("SaveMovies() error = %v", err)
+	}
+	if err := store.SaveShowtimes([]models.Showtime{
+		{ID: "1", TheaterID: "cst", TMDBID: 348, Date: "2026-03-09", Time: "21:00"},
+		{ID: "2", TheaterID: "cst", TMDBID: 348, Date: "2026-03-08", Time: "21:00"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+
+	// Already March 10th in UTC, but still the evening of the 9th in Portland
+	handler := NewHandler(store, scrapers.NewRegistry(), tmdb.NewClient(tmdb.Config{CacheTTL: time.Hour}))
+	handler.now = func() time.Time { return time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC) }
+	router := SetupRouter(handler, testOrigins)
+
+	var details MovieDetails
+	if code := get(t, router, "/api/movies/348", &details); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(details.Theaters) != 1 || len(details.Theaters[0].Dates) != 1 || details.Theaters[0].Dates[0].Date != "2026-03-09" {
+		t.Fatalf("theaters = %+v, want only the showtime on 2026-03-09", details.Theaters)
+	}
+}
+
+func TestGetMovieDetails_InvalidID(t *testing.T) {
+	router := newTestRouter(t, nil)
+
+	for _, path := range []string{"/api/movies/abc", "/api/movies/0", "/api/movies/-5"} {
+		if code := get(t, router, path, nil); code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, code)
+		}
+	}
+}
+
+func TestGetMovieDetails_TMDBErrors(t *testing.T) {
+	const key = "secret-key"
+
+	tests := []struct {
+		tmdbStatus int
+		want       int
+	}{
+		{http.StatusNotFound, http.StatusNotFound},
+		{http.StatusUnauthorized, http.StatusBadGateway},
+		{http.StatusInternalServerError, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.tmdbStatus)
+			fmt.Fprintf(w, `{"status_message": "request %s failed"}`, r.URL)
+		}))
+		router := newTMDBRouter(t, nil, tmdb.NewClient(tmdb.Config{APIKey: key, CacheTTL: time.Hour, BaseURL: server.URL}))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/movies/348", nil))
+		server.Close()
+
+		if rec.Code != tt.want {
+			t.Errorf("TMDB status %d: status = %d, want %d", tt.tmdbStatus, rec.Code, tt.want)
+		}
+		if strings.Contains(rec.Body.String(), key) || strings.Contains(rec.Body.String(), "status_message") {
+			t.Errorf("TMDB status %d: body %s echoes the upstream error", tt.tmdbStatus, rec.Body.String())
+		}
+	}
+}
+
+func TestGetMovieDetails_TMDBDisabled(t *testing.T) {
+	router := newTestRouter(t, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/movies/348", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 for a movie not stored while TMDB is disabled", rec.Code)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	decodeJSON(t, rec, &body)
+	if body.Error != "TMDB is disabled; only stored movies are available" {
+		t.Errorf("error = %q", body.Error)
+	}
+}
+
+func TestHealth_ReportsTMDBDisabled(t *testing.T) {
+	router := newTestRouter(t, nil)
+
+	var body struct {
+		TMDB string `json:"tmdb"`
+	}
+	if code := get(t, router, "/api/health", &body); code != http.StatusOK {
+		t.Fatalf("GET /api/health status = %d, want 200", code)
+	}
+	if body.TMDB != "disabled" {
+		t.Errorf("tmdb = %q, want disabled without credentials", body.TMDB)
+	}
+}
+
+func TestSetupRouter_CORSOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newScrapeHandler(t)
+
+	tests := []struct {
+		origins []string
+		origin  string
+		allowed string
+	}{
+		{[]string{"https://showtimes.example.com"}, "https://showtimes.example.com", "https://showtimes.example.com"},
+		{[]string{"https://showtimes.example.com"}, "http://localhost:3000", ""},
+		{[]string{"*"}, "http://localhost:3000", "*"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		SetupRouter(handler, tt.origins).ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+			t.Errorf("origins %v, Origin %s: Access-Control-Allow-Origin = %q, want %q", tt.origins, tt.origin, got, tt.allowed)
+		}
+	}
+}
+
+// stubScraper returns one showtime for its theater
+type stubScraper struct {
+	id string
+}
+
+func (s *stubScraper) GetTheaterInfo() models.Theater {
+	return models.Theater{ID: s.id, Name: s.id}
+}
+
+func (s *stubScraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
+	showtime := models.Showtime{ID: s.id + "-1", MovieTitle: "Alien", Date: "2026-03-01", Time: "19:00"}
+	opts.EmitShowtime(showtime)
+	return []models.Showtime{showtime}, nil
+}
+
+func (s *stubScraper) GetID() string {
+	return s.id
+}
+
+// newScrapeHandler builds a handler whose registry holds a stub "cst" scraper
+// and whose pipeline skips TMDB
+func newScrapeHandler(t *testing.T) *Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	registry := scrapers.NewRegistry()
+	registry.Register(&stubScraper{id: "cst"})
+
+	handler := &Handler{storage: store, registry: registry, pipeline: pipeline.New(store, nil, nil), events: events.NewBus()}
+	handler.jobs = jobs.NewManager(handler.runScrape, handler.events.Publish)
+	return handler
+}
+
+func TestTriggerScrape_RunsJob(t *testing.T) {
+	handler := newScrapeHandler(t)
+	router := SetupRouter(handler, testOrigins)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/scrape", strings.NewReader(`{"theater_ids":["missing"]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown theater status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/scrape", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	var job jobs.Job
+	decodeJSON(t, rec, &job)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := handler.jobs.Wait(ctx, job.ID); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if code := get(t, router, rec.Header().Get("Location"), &job); code != http.StatusOK {
+		t.Fatalf("GET job status = %d, want 200", code)
+	}
+	if job.Status != jobs.StatusSucceeded || len(job.Results) != 1 || job.Summary.TotalShowtimes != 1 {
+		t.Errorf("job = %+v, want one succeeded theater with 1 showtime", job)
+	}
+	if code := get(t, router, "/api/scrape/jobs/unknown", nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown job status = %d, want 404", code)
+	}
+}
+
+func TestScrapeHistory_SurfacesPartialScrapes(t *testing.T) {
+	handler := newScrapeHandler(t)
+	router := SetupRouter(handler, testOrigins)
+
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, metadata := range []models.ScrapeMetadata{
+		{TheaterID: "cst", Status: models.ScrapeSuccess},
+		{TheaterID: "other", Status: models.ScrapeSuccess},
+		{TheaterID: "cst", Status: models.ScrapePartial, FailedURLs: []string{"https://cstpdx.com/schedule/month/2026-04/"}},
+	} {
+		metadata.LastUpdated = started.Add(time.Duration(i) * time.Hour)
+		if err := handler.storage.SaveMetadata(metadata); err != nil {
+			t.Fatalf("SaveMetadata() error = %v", err)
+		}
+	}
+
+	var history []models.ScrapeMetadata
+	if code := get(t, router, "/api/scrape/history?theater=cst", &history); code != http.StatusOK {
+		t.Fatalf("GET history status = %d, want 200", code)
+	}
+	if len(history) != 2 || history[0].Status != models.ScrapePartial || len(history[0].FailedURLs) != 1 {
+		t.Errorf("history = %+v, want the partial cst scrape first", history)
+	}
+
+	if code := get(t, router, "/api/scrape/history?limit=1", &history); code != http.StatusOK || len(history) != 1 {
+		t.Errorf("GET limited history = %d with %d records, want 200 with 1", code, len(history))
+	}
+	if code := get(t, router, "/api/scrape/history?limit=0", nil); code != http.StatusBadRequest {
+		t.Errorf("GET history with limit=0 status = %d, want 400", code)
+	}
+
+	var lastUpdated struct {
+		LastUpdated time.Time               `json:"last_updated"`
+		Theaters    []models.ScrapeMetadata `json:"theaters"`
+	}
+	if code := get(t, router, "/api/last-updated", &lastUpdated); code != http.StatusOK {
+		t.Fatalf("GET last-updated status = %d, want 200", code)
+	}
+	if len(lastUpdated.Theaters) != 1 || lastUpdated.Theaters[0].Status != models.ScrapePartial {
+		t.Errorf("theaters = %+v, want only the registered cst with its partial scrape", lastUpdated.Theaters)
+	}
+}
+
+func TestGetScrapersHealth(t *testing.T) {
+	handler := newScrapeHandler(t)
+	handler.registry.Register(&stubScraper{id: "idle"})
+	router := SetupRouter(handler, testOrigins)
+
+	for _, count := range []int{39, 40, 41, 0} {
+		metadata := models.ScrapeMetadata{TheaterID: "cst", Status: models.ScrapeSuccess, ShowtimesScraped: count}
+		if count == 0 {
+			metadata.Anomalies = []models.Anomaly{{Kind: health.ZeroShowtimes, Message: "no showtimes found, usually 40", Baseline: 40}}
+		}
+		if err := handler.storage.SaveMetadata(metadata); err != nil {
+			t.Fatalf("SaveMetadata() error = %v", err)
+		}
+	}
+
+	var got struct {
+		Status   health.State    `json:"status"`
+		Scrapers []health.Status `json:"scrapers"`
+	}
+	if code := get(t, router, "/api/scrapers/health", &got); code != http.StatusOK {
+		t.Fatalf("GET scrapers health status = %d, want 200", code)
+	}
+	if got.Status != health.StateDegraded || len(got.Scrapers) != 2 {
+		t.Fatalf("health = %+v, want degraded with two scrapers", got)
+	}
+	if cst := got.Scrapers[0]; cst.TheaterID != "cst" || cst.State != health.StateDegraded || cst.BaselineShowtimes != 40 {
+		t.Errorf("cst = %+v, want degraded with a baseline of 40", cst)
+	}
+	if idle := got.Scrapers[1]; idle.TheaterID != "idle" || idle.State != health.StateUnknown {
+		t.Errorf("idle = %+v, want unknown", idle)
+	}
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
+)
+
+// maxPageSize caps the limit query parameter
+const maxPageSize = 500
+
+// totalCountHeader carries the number of items before pagination
+const totalCountHeader = "X-Total-Count"
+
+// sortKey is one field of a sort parameter such as "-tmdb_rating"
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// listOptions holds the sort, pagination and field selection parameters
+// shared by the list endpoints
+type listOptions struct {
+	sort   []sortKey
+	offset int
+	limit  int // 0 returns everything after offset
+	fields []string
+}
+
+// sortFields maps the sortable JSON field names of T to comparators
+type sortFields[T any] map[string]func(a, b T) int
+
+var showtimeSortFields = sortFields[models.Showtime]{
+	"id":          func(a, b models.Showtime) int { return cmp.Compare(a.ID, b.ID) },
+	"theater_id":  func(a, b models.Showtime) int { return cmp.Compare(a.TheaterID, b.TheaterID) },
+	"movie_title": func(a, b models.Showtime) int { return compareFold(a.MovieTitle, b.MovieTitle) },
+	"tmdb_id":     func(a, b models.Showtime) int { return cmp.Compare(a.TMDBID, b.TMDBID) },
+	"date":        func(a, b models.Showtime) int { return cmp.Compare(a.Date, b.Date) },
+	"time":        compareClocks,
+	"starts_at":   compareStarts,
+	"ends_at":     compareEnds,
+	"format":      func(a, b models.Showtime) int { return compareFold(a.Format, b.Format) },
+	"price":       func(a, b models.Showtime) int { return cmp.Compare(a.Price, b.Price) },
+}
+
+var showtimeDefaultSort = []sortKey{{field: "date"}, {field: "starts_at"}, {field: "theater_id"}, {field: "movie_title"}, {field: "id"}}
+
+var movieSortFields = sortFields[models.Movie]{
+	"tmdb_id":      func(a, b models.Movie) int { return cmp.Compare(a.TMDBID, b.TMDBID) },
+	"title":        func(a, b models.Movie) int { return compareFold(a.Title, b.Title) },
+	"runtime":      func(a, b models.Movie) int { return cmp.Compare(a.Runtime, b.Runtime) },
+	"release_date": func(a, b models.Movie) int { return cmp.Compare(a.ReleaseDate, b.ReleaseDate) },
+	"tmdb_rating":  func(a, b models.Movie) int { return cmp.Compare(a.TMDBRating, b.TMDBRating) },
+	"vote_count":   func(a, b models.Movie) int { return cmp.Compare(a.VoteCount, b.VoteCount) },
+	"popularity":   func(a, b models.Movie) int { return cmp.Compare(a.Popularity, b.Popularity) },
+}
+
+var movieDefaultSort = []sortKey{{field: "title"}, {field: "tmdb_id"}}
+
+var theaterSortFields = sortFields[models.Theater]{
+	"id":   func(a, b models.Theater) int { return cmp.Compare(a.ID, b.ID) },
+	"name": func(a, b models.Theater) int { return compareFold(a.Name, b.Name) },
+	"city": func(a, b models.Theater) int { return compareFold(a.City, b.City) },
+	"zip":  func(a, b models.Theater) int { return cmp.Compare(a.Zip, b.Zip) },
+}
+
+var theaterDefaultSort = []sortKey{{field: "name"}, {field: "id"}}
+
+// parseListOptions reads the sort, offset, limit and fields query parameters.
+// The returned error is meant for the client and should be sent as a 400.
+func parseListOptions[T any](c *gin.Context, sortable sortFields[T]) (listOptions, error) {
+	var opts listOptions
+
+	for _, field := range listParam(c, "sort") {
+		key := sortKey{field: field}
+		if strings.HasPrefix(field, "-") {
+			key = sortKey{field: field[1:], desc: true}
+		}
+		if _, ok := sortable[key.field]; !ok {
+			return opts, fmt.Errorf("cannot sort by %q; sortable fields are %s", key.field, strings.Join(sortable.names(), ", "))
+		}
+		opts.sort = append(opts.sort, key)
+	}
+
+	var err error
+	if value := c.Query("offset"); value != "" {
+		opts.offset, err = strconv.Atoi(value)
+		if err != nil || opts.offset < 0 {
+			return opts, fmt.Errorf("offset must be a non-negative integer, got %q", value)
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		opts.limit, err = strconv.Atoi(value)
+		if err != nil || opts.limit <= 0 || opts.limit > maxPageSize {
+			return opts, fmt.Errorf("limit must be an integer between 1 and %d, got %q", maxPageSize, value)
+		}
+	}
+
+	opts.fields = listParam(c, "fields")
+	known := jsonFieldNames(reflect.TypeOf((*T)(nil)).Elem())
+	for _, field := range opts.fields {
+		if !known[field] {
+			return opts, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	return opts, nil
+}
+
+// respondList sorts items by the requested keys (falling back to
+// defaultSort for ties), reports the total in X-Total-Count, and writes
+// the requested page with only the selected fields
+func respondList[T any](c *gin.Context, items []T, opts listOptions, sortable sortFields[T], defaultSort []sortKey) {
+	keys := append(append([]sortKey(nil), opts.sort...), defaultSort...)
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			order := sortable[key.field](items[i], items[j])
+			if key.desc {
+				order = -order
+			}
+			if order != 0 {
+				return order < 0
+			}
+		}
+		return false
+	})
+
+	c.Header(totalCountHeader, strconv.Itoa(len(items)))
+
+	page := items[min(opts.offset, len(items)):]
+	if opts.limit > 0 && opts.limit < len(page) {
+		page = page[:opts.limit]
+	}
+
+	if len(opts.fields) == 0 {
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	projected, err := selectFields(page, opts.fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, projected)
+}
+
+// selectFields re-encodes items as JSON objects holding only fields
+func selectFields[T any](items []T, fields []string) ([]map[string]json.RawMessage, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode items: %w", err)
+	}
+
+	var full []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &full); err != nil {
+		return nil, fmt.Errorf("failed to decode items: %w", err)
+	}
+
+	projected := make([]map[string]json.RawMessage, len(full))
+	for i, item := range full {
+		projected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, exists := item[field]; exists {
+				projected[i][field] = value
+			}
+		}
+	}
+	return projected, nil
+}
+
+// jsonFieldNames returns the JSON names of a struct type's exported fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// names returns the sortable field names in alphabetical order
+func (s sortFields[T]) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compareFold compares two strings case-insensitively
+func compareFold(a, b string) int {
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareStarts orders showtimes by start time, with unknown times last
+func compareStarts(a, b models.Showtime) int {
+	startA, knownA := startOf(a)
+	startB, knownB := startOf(b)
+	return compareKnown(knownA, knownB, func() int { return startA.Compare(startB) })
+}
+
+// compareEnds orders showtimes by end time, with unknown end times last
+func compareEnds(a, b models.Showtime) int {
+	return compareKnown(a.EndsAt != nil, b.EndsAt != nil, func() int { return a.EndsAt.Compare(*b.EndsAt) })
+}
+
+// compareClocks orders showtimes by time of day in the theaters' time zone,
+// with unknown times last
+func compareClocks(a, b models.Showtime) int {
+	startA, knownA := startOf(a)
+	startB, knownB := startOf(b)
+	return compareKnown(knownA, knownB, func() int {
+		return cmp.Compare(startA.In(scrapers.Location).Format("15:04"), startB.In(scrapers.Location).Format("15:04"))
+	})
+}
+
+// compareKnown sorts known values before unknown ones, comparing two known
+// values with compare
+func compareKnown(knownA, knownB bool, compare func() int) int {
+	switch {
+	case knownA && knownB:
+		return compare()
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return 0
+}
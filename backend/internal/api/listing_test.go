@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/storage"
+)
+
+func TestListEndpoints_Sorting(t *testing.T) {
+	router := newTestRouter(t, seedFilterData)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/showtimes?sort=-date,time", []string{"4", "5", "3", "2", "1"}},
+		{"/api/showtimes?sort=-starts_at", []string{"5", "4", "3", "2", "1"}},
+		{"/api/showtimes?sort=time", []string{"3", "4", "1", "2", "5"}},
+		{"/api/showtimes?sort=format", []string{"5", "1", "4", "2", "3"}},
+		{"/api/showtimes?sort=movie_title,-date", []string{"3", "1", "4", "2", "5"}},
+	}
+
+	for _, tt := range tests {
+		var got []models.Showtime
+		if code := get(t, router, tt.path, &got); code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200", tt.path, code)
+		}
+		ids := make([]string, len(got))
+		for i, st := range got {
+			ids[i] = st.ID
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("GET %s = %v, want %v", tt.path, ids, tt.want)
+		}
+	}
+
+	var movies []models.Movie
+	get(t, router, "/api/movies?sort=-tmdb_rating", &movies)
+	if len(movies) != 3 || movies[0].TMDBID != 348 || movies[2].TMDBID != 620 {
+		t.Errorf("movies by -tmdb_rating = %+v, want Alien first and Ghostbusters last", movies)
+	}
+
+	// Without a sort parameter movies come back by title, on every call
+	for i := 0; i < 3; i++ {
+		get(t, router, "/api/movies", &movies)
+		if movies[0].Title != "Alien" || movies[1].Title != "Ghostbusters" || movies[2].Title != "Heat" {
+			t.Fatalf("default movie order = %+v, want by title", movies)
+		}
+	}
+}
+
+func TestListEndpoints_Pagination(t *testing.T) {
+	router := newTestRouter(t, seedFilterData)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"limit=2", []string{"1", "2"}},
+		{"offset=2&limit=2", []string{"3", "4"}},
+		{"offset=4&limit=2", []string{"5"}},
+		{"offset=10", []string{}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/showtimes?"+tt.query, nil))
+
+		if got := rec.Header().Get(totalCountHeader); got != "5" {
+			t.Errorf("%s: %s = %q, want 5", tt.query, totalCountHeader, got)
+		}
+
+		var got []models.Showtime
+		decodeJSON(t, rec, &got)
+		ids := make([]string, len(got))
+		for i, st := range got {
+			ids[i] = st.ID
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+func TestListEndpoints_FieldSelection(t *testing.T) {
+	router := newTestRouter(t, func(t *testing.T, store storage.Store) {
+		if err := store.SaveMovies([]models.Movie{{TMDBID: 348, Title: "Alien", Overview: "In space...", Cast: []string{"Sigourney Weaver"}}}); err != nil {
+			t.Fatalf("SaveMovies() error = %v", err)
+		}
+	})
+
+	var got []map[string]interface{}
+	if code := get(t, router, "/api/movies?fields=tmdb_id,title,poster_path", &got); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	want := []map[string]interface{}{{"tmdb_id": float64(348), "title": "Alien", "poster_path": ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestListEndpoints_InvalidOptions(t *testing.T) {
+	router := newTestRouter(t, nil)
+
+	paths := []string{
+		"/api/showtimes?sort=overview",
+		"/api/movies?sort=-cast",
+		"/api/theaters?offset=-1",
+		"/api/theaters?limit=0",
+		"/api/showtimes?limit=100000",
+		"/api/movies?fields=title,secret",
+		"/api/showtimes/cst?sort=nope",
+	}
+
+	for _, path := range paths {
+		if code := get(t, router, path, nil); code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, code)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"sort"
+
+	"theater-showtimes/internal/models"
+)
+
+// MovieDetails is a movie with its upcoming showtimes grouped by theater and date
+type MovieDetails struct {
+	models.Movie
+	Theaters []TheaterSchedule `json:"theaters"`
+}
+
+// TheaterSchedule lists one theater's showtimes for a movie, by date
+type TheaterSchedule struct {
+	Theater models.Theater `json:"theater"`
+	Dates   []DateSchedule `json:"dates"`
+}
+
+// DateSchedule lists the showtimes on one date
+type DateSchedule struct {
+	Date      string            `json:"date"`
+	Showtimes []models.Showtime `json:"showtimes"`
+}
+
+// groupShowtimes groups showtimes by theater (ordered by name) and date
+// (ascending), with each day's showtimes ordered by start time and unknown
+// times last. Theaters missing from theaters are still listed, identified
+// only by ID.
+func groupShowtimes(showtimes []models.Showtime, theaters []models.Theater) []TheaterSchedule {
+	theaterByID := make(map[string]models.Theater, len(theaters))
+	for _, theater := range theaters {
+		theaterByID[theater.ID] = theater
+	}
+
+	byTheater := make(map[string]map[string][]models.Showtime)
+	for _, st := range showtimes {
+		if byTheater[st.TheaterID] == nil {
+			byTheater[st.TheaterID] = make(map[string][]models.Showtime)
+		}
+		byTheater[st.TheaterID][st.Date] = append(byTheater[st.TheaterID][st.Date], st)
+	}
+
+	schedules := make([]TheaterSchedule, 0, len(byTheater))
+	for theaterID, byDate := range byTheater {
+		theater, exists := theaterByID[theaterID]
+		if !exists {
+			theater = models.Theater{ID: theaterID}
+		}
+
+		schedule := TheaterSchedule{Theater: theater}
+		for date, dayShowtimes := range byDate {
+			sort.SliceStable(dayShowtimes, func(i, j int) bool {
+				return compareStarts(dayShowtimes[i], dayShowtimes[j]) < 0
+			})
+			schedule.Dates = append(schedule.Dates, DateSchedule{Date: date, Showtimes: dayShowtimes})
+		}
+		sort.Slice(schedule.Dates, func(i, j int) bool {
+			return schedule.Dates[i].Date < schedule.Dates[j].Date
+		})
+
+		schedules = append(schedules, schedule)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Theater.Name != schedules[j].Theater.Name {
+			return schedules[i].Theater.Name < schedules[j].Theater.Name
+		}
+		return schedules[i].Theater.ID < schedules[j].Theater.ID
+	})
+
+	return schedules
+}
@@ -5,15 +5,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRouter configures and returns the Gin router
-func SetupRouter(handler *Handler) *gin.Engine {
+// SetupRouter configures and returns the Gin router, allowing cross-origin
+// requests from corsOrigins ("*" allows any origin)
+func SetupRouter(handler *Handler, corsOrigins []string) *gin.Engine {
 	router := gin.Default()
 
 	// CORS configuration
 	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:3000"}
+	for _, origin := range corsOrigins {
+		if origin == "*" {
+			config.AllowAllOrigins = true
+		}
+	}
+	if !config.AllowAllOrigins {
+		config.AllowOrigins = corsOrigins
+	}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept"}
+	config.ExposeHeaders = []string{totalCountHeader}
 	router.Use(cors.New(config))
 
 	// API routes
@@ -30,6 +39,13 @@ func SetupRouter(handler *Handler) *gin.Engine {
 		api.GET("/movies/:id", handler.GetMovieDetails)
 		
 		api.POST("/scrape", handler.TriggerScrape)
+		api.GET("/scrape/jobs", handler.ListScrapeJobs)
+		api.GET("/scrape/jobs/:id", handler.GetScrapeJob)
+		api.DELETE("/scrape/jobs/:id", handler.CancelScrapeJob)
+		api.GET("/scrape/events", handler.StreamScrapeEvents)
+		api.GET("/scrape/history", handler.GetScrapeHistory)
+		api.GET("/schedule", handler.GetSchedule)
+		api.GET("/scrapers/health", handler.GetScrapersHealth)
 	}
 
 	return router
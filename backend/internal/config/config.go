@@ -0,0 +1,363 @@
+// Package config loads the settings shared by the API server and the
+// scraper CLI from a YAML file, with environment variables taking
+// precedence over the file and built-in defaults filling in the rest.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"theater-showtimes/internal/storage"
+	"theater-showtimes/internal/tmdb"
+)
+
+// DefaultPath is the config file read, if it exists, when none is given
+const DefaultPath = "configs/config.yaml"
+
+// Config holds every setting the commands read at startup
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Storage   Storage   `yaml:"storage"`
+	TMDB      TMDB      `yaml:"tmdb"`
+	Scraper   Scraper   `yaml:"scraper"`
+	Scheduler Scheduler `yaml:"scheduler"`
+	Theaters  []Theater `yaml:"theaters"`
+}
+
+// Server configures the HTTP API
+type Server struct {
+	Address     string   `yaml:"address"`      // SERVER_ADDRESS
+	CORSOrigins []string `yaml:"cors_origins"` // CORS_ORIGINS, comma-separated; "*" allows any
+}
+
+// Storage selects where scraped data is kept
+type Storage struct {
+	Backend string `yaml:"backend"` // STORAGE_TYPE: json or sqlite
+	Path    string `yaml:"path"`    // DATA_PATH
+}
+
+// TMDB configures movie lookups
+type TMDB struct {
+	// Enabled turns lookups on or off. Unset, they run whenever a
+	// credential is configured; true requires one. TMDB_ENABLED.
+	Enabled *bool `yaml:"enabled"`
+
+	// Credentials are best set in the environment rather than the file
+	APIKey      string `yaml:"api_key"`      // TMDB_API_KEY, v3 API key
+	AccessToken string `yaml:"access_token"` // TMDB_ACCESS_TOKEN, v4 read access token
+
+	CacheTTL time.Duration `yaml:"cache_ttl"` // TMDB_CACHE_TTL
+}
+
+// ClientConfig returns the settings for tmdb.NewClient. The credentials are
+// left out when lookups are turned off, which disables the client.
+func (t TMDB) ClientConfig() tmdb.Config {
+	cfg := tmdb.Config{CacheTTL: t.CacheTTL}
+	if t.Enabled == nil || *t.Enabled {
+		cfg.APIKey = t.APIKey
+		cfg.AccessToken = t.AccessToken
+	}
+	return cfg
+}
+
+// Scraper holds the defaults for every scrape
+type Scraper struct {
+	Concurrency int `yaml:"concurrency"` // SCRAPE_CONCURRENCY
+
+	// RateLimit is the longest random delay between requests to a site;
+	// zero keeps each scraper's own delay. SCRAPE_RATE_LIMIT.
+	RateLimit time.Duration `yaml:"rate_limit"`
+}
+
+// Scheduler configures recurring scrapes in the API server
+type Scheduler struct {
+	Enabled         bool          `yaml:"enabled"`          // SCHEDULER_ENABLED
+	Jitter          time.Duration `yaml:"jitter"`           // SCHEDULER_JITTER
+	DefaultSchedule string        `yaml:"default_schedule"` // SCHEDULER_DEFAULT_SCHEDULE
+}
+
+// Theater overrides the scrape settings for one scraper, by ID
+type Theater struct {
+	ID string `yaml:"id"`
+
+	// Enabled defaults to true; disabled scrapers are not registered by
+	// the API server and are skipped by the CLI unless named
+	Enabled *bool `yaml:"enabled"`
+
+	// Schedule replaces the scheduler's default_schedule
+	Schedule string `yaml:"schedule"`
+
+	// RateLimit replaces the scraper section's rate_limit
+	RateLimit time.Duration `yaml:"rate_limit"`
+}
+
+// Default returns the settings used when neither the file nor the
+// environment sets a value
+func Default() Config {
+	return Config{
+		Server: Server{
+			Address:     ":8080",
+			CORSOrigins: []string{"http://localhost:3000"},
+		},
+		Storage: Storage{
+			Backend: storage.BackendJSON,
+			Path:    "./data",
+		},
+		TMDB: TMDB{
+			CacheTTL: 168 * time.Hour,
+		},
+		Scraper: Scraper{
+			Concurrency: 4,
+		},
+		Scheduler: Scheduler{
+			Enabled: true,
+			Jitter:  5 * time.Minute,
+			// Nightly, in Portland time
+			DefaultSchedule: "CRON_TZ=America/Los_Angeles 0 3 * * *",
+		},
+		Theaters: []Theater{
+			// Clinton Street's listings change more often than the others'
+			{ID: "clinton-street-theater", Schedule: "CRON_TZ=America/Los_Angeles 0 */6 * * *"},
+		},
+	}
+}
+
+// Load reads the config file at path over the defaults, applies environment
+// overrides and validates the result. An empty path reads DefaultPath if it
+// exists, so the commands run with defaults out of the box.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	required := path != ""
+	if !required {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		// The file lists its own theaters rather than adding to the defaults
+		cfg.Theaters = nil
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !required:
+	default:
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := cfg.applyEnv(os.Getenv); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides settings from environment variables read with getenv
+func (c *Config) applyEnv(getenv func(string) string) error {
+	var errs []error
+	str := func(key string, target *string) {
+		if value := getenv(key); value != "" {
+			*target = value
+		}
+	}
+	duration := func(key string, target *time.Duration) {
+		if value := getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 5m, got %q", key, value))
+				return
+			}
+			*target = d
+		}
+	}
+
+	str("SERVER_ADDRESS", &c.Server.Address)
+	if value := getenv("CORS_ORIGINS"); value != "" {
+		c.Server.CORSOrigins = nil
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.Server.CORSOrigins = append(c.Server.CORSOrigins, origin)
+			}
+		}
+	}
+	str("STORAGE_TYPE", &c.Storage.Backend)
+	str("DATA_PATH", &c.Storage.Path)
+	if value := getenv("TMDB_ENABLED"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("TMDB_ENABLED must be true or false, got %q", value))
+		}
+		c.TMDB.Enabled = &enabled
+	}
+	str("TMDB_API_KEY", &c.TMDB.APIKey)
+	str("TMDB_ACCESS_TOKEN", &c.TMDB.AccessToken)
+	duration("TMDB_CACHE_TTL", &c.TMDB.CacheTTL)
+	if value := getenv("SCRAPE_CONCURRENCY"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCRAPE_CONCURRENCY must be an integer, got %q", value))
+		}
+		c.Scraper.Concurrency = n
+	}
+	duration("SCRAPE_RATE_LIMIT", &c.Scraper.RateLimit)
+	if value := getenv("SCHEDULER_ENABLED"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCHEDULER_ENABLED must be true or false, got %q", value))
+		}
+		c.Scheduler.Enabled = enabled
+	}
+	duration("SCHEDULER_JITTER", &c.Scheduler.Jitter)
+	str("SCHEDULER_DEFAULT_SCHEDULE", &c.Scheduler.DefaultSchedule)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Address); err != nil {
+		fail("server.address must be host:port or :port, got %q", c.Server.Address)
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		fail("server.cors_origins must list at least one origin")
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			fail("server.cors_origins entry %q must be an http(s) origin such as http://localhost:3000, or *", origin)
+		}
+	}
+
+	if c.Storage.Backend != storage.BackendJSON && c.Storage.Backend != storage.BackendSQLite {
+		fail("storage.backend must be %q or %q, got %q", storage.BackendJSON, storage.BackendSQLite, c.Storage.Backend)
+	}
+	if c.Storage.Path == "" {
+		fail("storage.path must not be empty")
+	}
+
+	if c.TMDB.Enabled != nil && *c.TMDB.Enabled && c.TMDB.APIKey == "" && c.TMDB.AccessToken == "" {
+		fail("tmdb.enabled is true but neither TMDB_API_KEY nor TMDB_ACCESS_TOKEN is set")
+	}
+	if c.TMDB.CacheTTL <= 0 {
+		fail("tmdb.cache_ttl must be positive, got %s", c.TMDB.CacheTTL)
+	}
+
+	if c.Scraper.Concurrency < 1 {
+		fail("scraper.concurrency must be at least 1, got %d", c.Scraper.Concurrency)
+	}
+	if c.Scraper.RateLimit < 0 {
+		fail("scraper.rate_limit must not be negative, got %s", c.Scraper.RateLimit)
+	}
+
+	if c.Scheduler.Jitter < 0 {
+		fail("scheduler.jitter must not be negative, got %s", c.Scheduler.Jitter)
+	}
+	if _, err := cron.ParseStandard(c.Scheduler.DefaultSchedule); err != nil {
+		fail("scheduler.default_schedule %q is invalid: %v", c.Scheduler.DefaultSchedule, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, theater := range c.Theaters {
+		if theater.ID == "" {
+			fail("theaters[%d] has no id", i)
+			continue
+		}
+		if seen[theater.ID] {
+			fail("theater %s is listed more than once", theater.ID)
+		}
+		seen[theater.ID] = true
+		if theater.Schedule != "" {
+			if _, err := cron.ParseStandard(theater.Schedule); err != nil {
+				fail("theater %s schedule %q is invalid: %v", theater.ID, theater.Schedule, err)
+			}
+		}
+		if theater.RateLimit < 0 {
+			fail("theater %s rate_limit must not be negative, got %s", theater.ID, theater.RateLimit)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// CheckTheaters reports theaters configured under IDs no scraper has, which
+// are most likely typos
+func (c Config) CheckTheaters(known []string) error {
+	registered := make(map[string]bool, len(known))
+	for _, id := range known {
+		registered[id] = true
+	}
+
+	var unknown []string
+	for _, theater := range c.Theaters {
+		if !registered[theater.ID] {
+			unknown = append(unknown, theater.ID)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("config lists unknown theaters %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// Enabled reports whether the scraper with id should run
+func (c Config) Enabled(id string) bool {
+	theater := c.theater(id)
+	return theater.Enabled == nil || *theater.Enabled
+}
+
+// Schedule returns the cron spec the scraper with id runs on
+func (c Config) Schedule(id string) string {
+	if schedule := c.theater(id).Schedule; schedule != "" {
+		return schedule
+	}
+	return c.Scheduler.DefaultSchedule
+}
+
+// RateLimit returns the request delay for the scraper with id; zero keeps
+// the scraper's own
+func (c Config) RateLimit(id string) time.Duration {
+	if limit := c.theater(id).RateLimit; limit > 0 {
+		return limit
+	}
+	return c.Scraper.RateLimit
+}
+
+// theater returns the settings listed for id, or empty settings
+func (c Config) theater(id string) Theater {
+	for _, theater := range c.Theaters {
+		if theater.ID == id {
+			return theater
+		}
+	}
+	return Theater{ID: id}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/tmdb"
+)
+
+// writeConfig writes a config file into a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_FileOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  address: "127.0.0.1:9090"
+storage:
+  backend: sqlite
+scraper:
+  rate_limit: 3s
+theaters:
+  - id: local-cinema
+    enabled: false
+  - id: example-theater
+    schedule: "@every 2h"
+    rate_limit: 500ms
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Server.Address != "127.0.0.1:9090" || cfg.Storage.Backend != "sqlite" {
+		t.Errorf("server/storage = %+v/%+v, want the file's values", cfg.Server, cfg.Storage)
+	}
+	if cfg.Storage.Path != "./data" || cfg.TMDB.CacheTTL != 168*time.Hour || cfg.Server.CORSOrigins[0] != "http://localhost:3000" {
+		t.Errorf("unset values = %+v, want defaults", cfg)
+	}
+
+	if cfg.Enabled("local-cinema") || !cfg.Enabled("example-theater") || !cfg.Enabled("unlisted") {
+		t.Error("Enabled() should be false only for local-cinema")
+	}
+	if got := cfg.Schedule("example-theater"); got != "@every 2h" {
+		t.Errorf("Schedule(example-theater) = %q, want @every 2h", got)
+	}
+	if got := cfg.Schedule("clinton-street-theater"); got != cfg.Scheduler.DefaultSchedule {
+		t.Errorf("Schedule(clinton-street-theater) = %q, want the default once the file lists its own theaters", got)
+	}
+	if got := cfg.RateLimit("example-theater"); got != 500*time.Millisecond {
+		t.Errorf("RateLimit(example-theater) = %s, want 500ms", got)
+	}
+	if got := cfg.RateLimit("local-cinema"); got != 3*time.Second {
+		t.Errorf("RateLimit(local-cinema) = %s, want the scraper default 3s", got)
+	}
+}
+
+func TestLoad_EnvironmentOverridesFile(t *testing.T) {
+	path := writeConfig(t, `
+storage:
+  path: /srv/showtimes
+scheduler:
+  enabled: true
+`)
+	t.Setenv("DATA_PATH", "/tmp/showtimes")
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("SCHEDULER_ENABLED", "false")
+	t.Setenv("SCRAPE_CONCURRENCY", "2")
+	t.Setenv("TMDB_CACHE_TTL", "24h")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Storage.Path != "/tmp/showtimes" {
+		t.Errorf("storage.path = %q, want the environment's", cfg.Storage.Path)
+	}
+	if strings.Join(cfg.Server.CORSOrigins, ",") != "https://a.example.com,https://b.example.com" {
+		t.Errorf("cors_origins = %v, want both environment origins", cfg.Server.CORSOrigins)
+	}
+	if cfg.Scheduler.Enabled || cfg.Scraper.Concurrency != 2 || cfg.TMDB.CacheTTL != 24*time.Hour {
+		t.Errorf("config = %+v, want scheduler disabled, concurrency 2 and a 24h cache", cfg)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir() error = %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load(\"\") without %s error = %v, want defaults", DefaultPath, err)
+	}
+	if got := cfg.Schedule("clinton-street-theater"); got != "CRON_TZ=America/Los_Angeles 0 */6 * * *" {
+		t.Errorf("default Clinton Street schedule = %q", got)
+	}
+
+	if _, err := Load("missing.yaml"); err == nil {
+		t.Error("Load(missing.yaml) error = nil, want an error for a named file that does not exist")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    []string
+	}{
+		{"unknown field", "storage:\n  kind: json\n", nil, []string{"field kind not found"}},
+		{"every problem reported", `
+server:
+  address: "8080"
+  cors_origins: ["localhost:3000", "https://ok.example.com/app"]
+storage:
+  backend: postgres
+  path: ""
+scraper:
+  concurrency: 0
+scheduler:
+  default_schedule: "every night"
+theaters:
+  - id: local-cinema
+    schedule: "0 25 * * *"
+  - id: local-cinema
+  - enabled: false
+`, nil, []string{
+			"server.address", `"localhost:3000"`, `"https://ok.example.com/app"`, "storage.backend", "storage.path",
+			"scraper.concurrency", "scheduler.default_schedule", "local-cinema schedule", "listed more than once", "theaters[2] has no id",
+		}},
+		{"bad environment", "", map[string]string{"SCHEDULER_JITTER": "soon", "SCHEDULER_ENABLED": "maybe"}, []string{"SCHEDULER_JITTER", "SCHEDULER_ENABLED"}},
+		{"tmdb enabled without credentials", "tmdb:\n  enabled: true\n", map[string]string{"TMDB_API_KEY": "", "TMDB_ACCESS_TOKEN": ""}, []string{"tmdb.enabled"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil {
+				t.Fatal("Load() error = nil, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %v, want it to mention %s", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTMDB_ClientConfig(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name        string
+		tmdb        TMDB
+		wantEnabled bool
+	}{
+		{"no credentials", TMDB{}, false},
+		{"api key", TMDB{APIKey: "key"}, true},
+		{"access token", TMDB{AccessToken: "token"}, true},
+		{"explicitly enabled", TMDB{Enabled: &on, AccessToken: "token"}, true},
+		{"turned off", TMDB{Enabled: &off, APIKey: "key", AccessToken: "token"}, false},
+	}
+
+	for _, tt := range tests {
+		client := tmdb.NewClient(tt.tmdb.ClientConfig())
+		if got := client.Enabled(); got != tt.wantEnabled {
+			t.Errorf("%s: client Enabled() = %v, want %v", tt.name, got, tt.wantEnabled)
+		}
+	}
+}
+
+func TestCheckTheaters(t *testing.T) {
+	cfg := Default()
+	cfg.Theaters = append(cfg.Theaters, Theater{ID: "clinton-st-theater"})
+
+	err := cfg.CheckTheaters([]string{"clinton-street-theater", "local-cinema"})
+	if err == nil || !strings.Contains(err.Error(), "clinton-st-theater") {
+		t.Errorf("CheckTheaters() error = %v, want the misspelled ID reported", err)
+	}
+	if err := Default().CheckTheaters([]string{"clinton-street-theater"}); err != nil {
+		t.Errorf("CheckTheaters() on defaults error = %v", err)
+	}
+}
+
+func TestLoad_ShippedConfig(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", DefaultPath))
+	if err != nil {
+		t.Fatalf("Load(%s) error = %v", DefaultPath, err)
+	}
+	if err := cfg.CheckTheaters([]string{"clinton-street-theater", "example-theater", "local-cinema"}); err != nil {
+		t.Errorf("CheckTheaters() error = %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before further events to it are dropped
+const subscriberBuffer = 256
+
+// Type identifies what happened during a scrape
+type Type string
+
+const (
+	JobStarted        Type = "job_started"
+	TheaterStarted    Type = "theater_started"
+	PageFetched       Type = "page_fetched"
+	PageRetry         Type = "page_retry"
+	PageFailed        Type = "page_failed"
+	ShowtimeExtracted Type = "showtime_extracted"
+	TMDBMatch         Type = "tmdb_match"
+	TMDBMiss          Type = "tmdb_miss"
+	TheaterFinished   Type = "theater_finished"
+	JobFinished       Type = "job_finished"
+)
+
+// Event is one step of scrape progress. Fields that do not apply to the
+// event type are left empty.
+type Event struct {
+	Type       Type             `json:"type"`
+	Time       time.Time        `json:"time"`
+	JobID      string           `json:"job_id,omitempty"`
+	TheaterID  string           `json:"theater_id,omitempty"`
+	URL        string           `json:"url,omitempty"`
+	StatusCode int              `json:"status_code,omitempty"`
+	Showtime   *models.Showtime `json:"showtime,omitempty"`
+	MovieTitle string           `json:"movie_title,omitempty"`
+	TMDBID     int              `json:"tmdb_id,omitempty"`
+	Status     string           `json:"status,omitempty"`
+	Error      string           `json:"error,omitempty"`
+
+	// Data carries the theater's scrape metadata on theater_finished and
+	// the job summary on job_finished
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Bus fans events out to any number of subscribers. Publishing never
+// blocks: a subscriber that falls too far behind misses events.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates an event bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends event to every subscriber, stamping its time if unset
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of future events and a function that
+// unsubscribes and closes the channel
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	first, unsubscribeFirst := bus.Subscribe()
+	second, unsubscribeSecond := bus.Subscribe()
+	defer unsubscribeSecond()
+
+	bus.Publish(Event{Type: JobStarted, JobID: "a"})
+
+	for _, ch := range []<-chan Event{first, second} {
+		event := <-ch
+		if event.Type != JobStarted || event.JobID != "a" || event.Time.IsZero() {
+			t.Errorf("received %+v, want stamped job_started for a", event)
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+
+	// Publishing after an unsubscribe must not panic on the closed channel
+	bus.Publish(Event{Type: JobFinished})
+	if event := <-second; event.Type != JobFinished {
+		t.Errorf("received %s, want job_finished", event.Type)
+	}
+}
+
+func TestBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		bus.Publish(Event{Type: PageFetched})
+	}
+
+	if len(ch) != subscriberBuffer {
+		t.Errorf("buffered %d events, want %d", len(ch), subscriberBuffer)
+	}
+}
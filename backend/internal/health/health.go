@@ -0,0 +1,156 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+// HistoryWindow is how many recent scrapes of a theater are compared against
+const HistoryWindow = 10
+
+// dropThreshold flags a run with fewer than this fraction of the usual showtimes
+const dropThreshold = 0.5
+
+// Anomaly kinds
+const (
+	ZeroShowtimes = "zero_showtimes"
+	ShowtimeDrop  = "showtime_drop"
+	MissingTimes  = "missing_times"
+)
+
+// State summarizes how a scraper is doing
+type State string
+
+const (
+	// StateHealthy means the last scrape succeeded and looked normal
+	StateHealthy State = "healthy"
+	// StateDegraded means the last scrape ran but was partial or anomalous
+	StateDegraded State = "degraded"
+	// StateFailing means the last scrape failed
+	StateFailing State = "failing"
+	// StateUnknown means the theater has never been scraped
+	StateUnknown State = "unknown"
+)
+
+// Status is the health of one scraper, derived from its scrape history
+type Status struct {
+	TheaterID           string                 `json:"theater_id"`
+	State               State                  `json:"state"`
+	Reasons             []string               `json:"reasons,omitempty"`
+	LastRun             *models.ScrapeMetadata `json:"last_run,omitempty"`
+	LastSuccess         *time.Time             `json:"last_success,omitempty"`
+	ConsecutiveFailures int                    `json:"consecutive_failures"`
+	BaselineShowtimes   int                    `json:"baseline_showtimes"`
+}
+
+// Baseline returns the median showtime count of the clean scrapes in
+// history: those that succeeded without anomalies. Partial and anomalous
+// runs are left out, so a broken scraper cannot become its own baseline.
+// Without a clean run it falls back to the baseline the newest anomalous
+// run was compared against, and reports false when there is none.
+func Baseline(history []models.ScrapeMetadata) (int, bool) {
+	counts := []int{}
+	for _, run := range history {
+		if run.Status == models.ScrapeSuccess && len(run.Anomalies) == 0 {
+			counts = append(counts, run.ShowtimesScraped)
+		}
+	}
+	if len(counts) > 0 {
+		sort.Ints(counts)
+		return counts[len(counts)/2], true
+	}
+
+	for _, run := range history {
+		for _, anomaly := range run.Anomalies {
+			if anomaly.Baseline > 0 {
+				return anomaly.Baseline, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Detect compares a scrape's showtimes with the theater's recent history,
+// newest first, and returns anything that suggests the scraper is broken
+func Detect(showtimes []models.Showtime, history []models.ScrapeMetadata) []models.Anomaly {
+	var anomalies []models.Anomaly
+	count := len(showtimes)
+
+	if baseline, ok := Baseline(history); ok && baseline > 0 {
+		switch {
+		case count == 0:
+			anomalies = append(anomalies, models.Anomaly{
+				Kind:     ZeroShowtimes,
+				Message:  fmt.Sprintf("no showtimes found, usually %d", baseline),
+				Baseline: baseline,
+			})
+		case float64(count) < float64(baseline)*dropThreshold:
+			anomalies = append(anomalies, models.Anomaly{
+				Kind:     ShowtimeDrop,
+				Message:  fmt.Sprintf("%d showtimes found, down more than half from the usual %d", count, baseline),
+				Baseline: baseline,
+			})
+		}
+	}
+
+	if count > 0 && !anyTimed(showtimes) {
+		anomalies = append(anomalies, models.Anomaly{
+			Kind:    MissingTimes,
+			Message: fmt.Sprintf("none of the %d showtimes has a time", count),
+		})
+	}
+
+	return anomalies
+}
+
+// Assess derives a scraper's health from its scrape history, newest first
+func Assess(theaterID string, history []models.ScrapeMetadata) Status {
+	status := Status{TheaterID: theaterID, State: StateUnknown}
+	if len(history) == 0 {
+		return status
+	}
+
+	last := history[0]
+	status.LastRun = &last
+	status.BaselineShowtimes, _ = Baseline(history)
+
+	for _, run := range history {
+		if run.Status != models.ScrapeError {
+			lastSuccess := run.LastUpdated
+			status.LastSuccess = &lastSuccess
+			break
+		}
+		status.ConsecutiveFailures++
+	}
+
+	switch {
+	case last.Status == models.ScrapeError:
+		status.State = StateFailing
+		status.Reasons = append(status.Reasons, last.ErrorMessage)
+	case last.Status == models.ScrapePartial || len(last.Anomalies) > 0:
+		status.State = StateDegraded
+		if last.Status == models.ScrapePartial {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("%d page(s) failed", len(last.FailedURLs)))
+		}
+		for _, anomaly := range last.Anomalies {
+			status.Reasons = append(status.Reasons, anomaly.Message)
+		}
+	default:
+		status.State = StateHealthy
+	}
+
+	return status
+}
+
+// anyTimed reports whether any showtime has a time
+func anyTimed(showtimes []models.Showtime) bool {
+	for _, st := range showtimes {
+		if st.Time != "" && !st.TimeUnknown {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package health
+
+import (
+	"reflect"
+	"testing"
+
+	"theater-showtimes/internal/models"
+)
+
+// runs builds a history of successful scrapes with the given showtime counts
+func runs(counts ...int) []models.ScrapeMetadata {
+	history := make([]models.ScrapeMetadata, len(counts))
+	for i, count := range counts {
+		history[i] = models.ScrapeMetadata{Status: models.ScrapeSuccess, ShowtimesScraped: count}
+	}
+	return history
+}
+
+// broken builds n scrapes that found nothing and were flagged against baseline
+func broken(n, baseline int) []models.ScrapeMetadata {
+	history := make([]models.ScrapeMetadata, n)
+	for i := range history {
+		history[i] = models.ScrapeMetadata{
+			Status:    models.ScrapeSuccess,
+			Anomalies: []models.Anomaly{{Kind: ZeroShowtimes, Message: "no showtimes found", Baseline: baseline}},
+		}
+	}
+	return history
+}
+
+// timed builds n showtimes, all with or all without a time
+func timed(n int, withTime bool) []models.Showtime {
+	showtimes := make([]models.Showtime, n)
+	for i := range showtimes {
+		if withTime {
+			showtimes[i].Time = "19:00"
+		}
+	}
+	return showtimes
+}
+
+func TestDetect(t *testing.T) {
+	failed := models.ScrapeMetadata{Status: models.ScrapeError}
+
+	tests := []struct {
+		name      string
+		showtimes []models.Showtime
+		history   []models.ScrapeMetadata
+		want      []string
+	}{
+		{"normal run", timed(38, true), runs(40, 42, 39), nil},
+		{"drop to zero", nil, runs(40, 42, 39), []string{ZeroShowtimes}},
+		{"drop by more than half", timed(15, true), runs(40, 42, 39), []string{ShowtimeDrop}},
+		{"drop by exactly half", timed(20, true), runs(40, 40, 40), nil},
+		{"no history", nil, nil, nil},
+		{"failed runs are not a baseline", nil, []models.ScrapeMetadata{failed, failed}, nil},
+		{"one outlier does not move the baseline", timed(38, true), runs(40, 400, 39), nil},
+		{"all times missing", timed(40, false), runs(40), []string{MissingTimes}},
+		{"zero and empty", nil, runs(0, 0), nil},
+		{"flagged zero runs are not a baseline", nil, append(broken(6, 40), runs(40, 40, 40, 40)...), []string{ZeroShowtimes}},
+		{"a window of flagged runs keeps their baseline", nil, broken(HistoryWindow, 40), []string{ZeroShowtimes}},
+		{"partial runs are not a baseline", timed(15, true), append([]models.ScrapeMetadata{
+			{Status: models.ScrapePartial, ShowtimesScraped: 10},
+			{Status: models.ScrapePartial, ShowtimesScraped: 10},
+		}, runs(40)...), []string{ShowtimeDrop}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []string
+			for _, anomaly := range Detect(tt.showtimes, tt.history) {
+				kinds = append(kinds, anomaly.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.want) {
+				t.Errorf("Detect() kinds = %v, want %v", kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssess(t *testing.T) {
+	failure := models.ScrapeMetadata{Status: models.ScrapeError, ErrorMessage: "site down"}
+	partial := models.ScrapeMetadata{Status: models.ScrapePartial, ShowtimesScraped: 30, FailedURLs: []string{"https://example.com/april"}}
+	anomalous := models.ScrapeMetadata{Status: models.ScrapeSuccess, Anomalies: []models.Anomaly{{Kind: ZeroShowtimes, Message: "no showtimes found, usually 40"}}}
+
+	tests := []struct {
+		name         string
+		history      []models.ScrapeMetadata
+		wantState    State
+		wantFailures int
+		wantReasons  []string
+	}{
+		{"never scraped", nil, StateUnknown, 0, nil},
+		{"healthy", runs(40, 41), StateHealthy, 0, nil},
+		{"partial", append([]models.ScrapeMetadata{partial}, runs(40)...), StateDegraded, 0, []string{"1 page(s) failed"}},
+		{"anomalous", append([]models.ScrapeMetadata{anomalous}, runs(40)...), StateDegraded, 0, []string{"no showtimes found, usually 40"}},
+		{"failing", append([]models.ScrapeMetadata{failure, failure}, runs(40)...), StateFailing, 2, []string{"site down"}},
+		{"recovered", append(runs(40), failure), StateHealthy, 0, nil},
+		{"still broken", append(broken(6, 40), runs(40, 40, 40, 40)...), StateDegraded, 0, []string{"no showtimes found"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Assess("cst", tt.history)
+			if got.State != tt.wantState || got.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("Assess() = %s with %d failures, want %s with %d", got.State, got.ConsecutiveFailures, tt.wantState, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(got.Reasons, tt.wantReasons) {
+				t.Errorf("Reasons = %v, want %v", got.Reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestDetect_KeepsFlaggingABrokenScraper(t *testing.T) {
+	history := runs(40, 41, 39, 40, 42, 40, 38, 40, 41, 40)
+
+	// Each empty run is detected against, then joins, the recent history
+	for i := 0; i < 2*HistoryWindow; i++ {
+		anomalies := Detect(nil, history)
+		if len(anomalies) != 1 || anomalies[0].Kind != ZeroShowtimes {
+			t.Fatalf("empty run %d anomalies = %+v, want zero_showtimes", i+1, anomalies)
+		}
+		run := models.ScrapeMetadata{Status: models.ScrapeSuccess, Anomalies: anomalies}
+		history = append([]models.ScrapeMetadata{run}, history[:HistoryWindow-1]...)
+	}
+
+	if got := Assess("cst", history); got.State != StateDegraded || got.BaselineShowtimes != 40 {
+		t.Errorf("Assess() = %s with baseline %d, want degraded against 40", got.State, got.BaselineShowtimes)
+	}
+}
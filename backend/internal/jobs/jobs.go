@@ -0,0 +1,330 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"theater-showtimes/internal/events"
+	"theater-showtimes/internal/pipeline"
+)
+
+// maxFinishedJobs is how many completed jobs are kept for status polling
+const maxFinishedJobs = 50
+
+// Status is the lifecycle state of a scrape job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	// ErrNotFound is returned for unknown or expired job IDs
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when cancelling a job that already finished
+	ErrFinished = errors.New("job already finished")
+)
+
+// ConflictError is returned when a job would scrape a theater that another
+// running job is already scraping
+type ConflictError struct {
+	JobID    string
+	Theaters []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("job %s is already scraping %s", e.JobID, strings.Join(e.Theaters, ", "))
+}
+
+// RunFunc runs the pipeline for job jobID over theaterIDs, calling progress
+// after each theater
+type RunFunc func(ctx context.Context, jobID string, theaterIDs []string, progress func(pipeline.TheaterResult)) pipeline.Report
+
+// Job is a snapshot of one asynchronous scrape
+type Job struct {
+	ID         string                   `json:"id"`
+	Status     Status                   `json:"status"`
+	TheaterIDs []string                 `json:"theater_ids"`
+	Pending    []string                 `json:"pending"`
+	Results    []pipeline.TheaterResult `json:"results"`
+	Summary    *Summary                 `json:"summary,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+}
+
+// Summary totals a finished job
+type Summary struct {
+	TotalShowtimes int `json:"total_showtimes"`
+	UniqueMovies   int `json:"unique_movies"`
+	Succeeded      int `json:"succeeded"`
+	Failed         int `json:"failed"`
+}
+
+// Active reports whether the job is still running
+func (j Job) Active() bool {
+	return j.Status == StatusRunning
+}
+
+// job is the mutable state behind a Job, guarded by Manager.mu
+type job struct {
+	Job
+	cancel    context.CancelFunc
+	cancelled bool
+	done      chan struct{}
+}
+
+// Manager runs scrape jobs in the background and tracks their progress.
+// Submitting the same theaters as a running job returns that job instead of
+// starting another; submitting an overlapping set is rejected.
+type Manager struct {
+	run     RunFunc
+	publish func(events.Event)
+
+	mu       sync.Mutex
+	jobs     map[string]*job
+	finished []string // IDs in completion order, oldest first
+}
+
+// NewManager creates a manager that executes jobs with run and reports
+// job_started and job_finished events to publish, which may be nil
+func NewManager(run RunFunc, publish func(events.Event)) *Manager {
+	if publish == nil {
+		publish = func(events.Event) {}
+	}
+
+	return &Manager{
+		run:     run,
+		publish: publish,
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Submit starts a job for theaterIDs. If a running job covers exactly the
+// same theaters it is returned with coalesced set; if one covers only some
+// of them a *ConflictError is returned.
+func (m *Manager) Submit(theaterIDs []string) (snapshot Job, coalesced bool, err error) {
+	theaterIDs = normalize(theaterIDs)
+	key := strings.Join(theaterIDs, ",")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.jobs {
+		if !existing.Active() {
+			continue
+		}
+		if strings.Join(existing.TheaterIDs, ",") == key {
+			return existing.snapshot(), true, nil
+		}
+		if shared := intersect(existing.TheaterIDs, theaterIDs); len(shared) > 0 {
+			return Job{}, false, &ConflictError{JobID: existing.ID, Theaters: shared}
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Job{}, false, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		Job: Job{
+			ID:         id,
+			Status:     StatusRunning,
+			TheaterIDs: theaterIDs,
+			Pending:    append([]string(nil), theaterIDs...),
+			Results:    []pipeline.TheaterResult{},
+			CreatedAt:  time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.jobs[id] = j
+
+	go m.execute(ctx, j)
+
+	return j.snapshot(), false, nil
+}
+
+// Get returns a snapshot of the job with the given ID
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, exists := m.jobs[id]
+	if !exists {
+		return Job{}, ErrNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// List returns snapshots of all tracked jobs, newest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		list = append(list, j.snapshot())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Cancel stops a running job. Theaters already scraped keep their results.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, exists := m.jobs[id]
+	if !exists {
+		return Job{}, ErrNotFound
+	}
+	if !j.Active() {
+		return j.snapshot(), ErrFinished
+	}
+
+	j.cancelled = true
+	j.cancel()
+	return j.snapshot(), nil
+}
+
+// Wait blocks until the job finishes or ctx is done, then returns its snapshot
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	j, exists := m.jobs[id]
+	m.mu.Unlock()
+	if !exists {
+		return Job{}, ErrNotFound
+	}
+
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+	return m.Get(id)
+}
+
+// execute runs the job and records its outcome
+func (m *Manager) execute(ctx context.Context, j *job) {
+	defer close(j.done)
+	defer j.cancel()
+
+	m.publish(events.Event{Type: events.JobStarted, JobID: j.ID, Status: string(StatusRunning)})
+
+	report := m.run(ctx, j.ID, j.TheaterIDs, func(result pipeline.TheaterResult) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		j.Results = append(j.Results, result)
+		j.Pending = remove(j.Pending, result.Metadata.TheaterID)
+	})
+
+	snap := m.finish(j, report)
+	m.publish(events.Event{Type: events.JobFinished, JobID: snap.ID, Status: string(snap.Status), Data: snap.Summary})
+}
+
+// finish records the job's outcome and expires the oldest finished jobs
+func (m *Manager) finish(j *job, report pipeline.Report) Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finished := time.Now()
+	j.FinishedAt = &finished
+	j.Pending = []string{}
+	j.Summary = &Summary{
+		TotalShowtimes: report.TotalShowtimes,
+		UniqueMovies:   report.UniqueMovies,
+		Succeeded:      report.Succeeded,
+		Failed:         report.Failed,
+	}
+
+	switch {
+	case j.cancelled:
+		j.Status = StatusCancelled
+	case report.Failed > 0 && report.Succeeded == 0:
+		j.Status = StatusFailed
+	default:
+		j.Status = StatusSucceeded
+	}
+
+	m.finished = append(m.finished, j.ID)
+	for len(m.finished) > maxFinishedJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+
+	return j.snapshot()
+}
+
+// snapshot copies the job so callers can read it without holding the lock
+func (j *job) snapshot() Job {
+	snap := j.Job
+	snap.TheaterIDs = append([]string(nil), j.TheaterIDs...)
+	snap.Pending = append([]string{}, j.Pending...)
+	snap.Results = append([]pipeline.TheaterResult{}, j.Results...)
+	if j.Summary != nil {
+		summary := *j.Summary
+		snap.Summary = &summary
+	}
+	return snap
+}
+
+// normalize sorts and de-duplicates theater IDs so equal sets compare equal
+func normalize(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			normalized = append(normalized, id)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// intersect returns the IDs present in both sorted lists
+func intersect(a, b []string) []string {
+	var shared []string
+	for _, id := range a {
+		i := sort.SearchStrings(b, id)
+		if i < len(b) && b[i] == id {
+			shared = append(shared, id)
+		}
+	}
+	return shared
+}
+
+// remove returns ids without id
+func remove(ids []string, id string) []string {
+	kept := ids[:0]
+	for _, existing := range ids {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+// newID returns a random job ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/pipeline"
+)
+
+// blockingRun reports each theater once release is closed, or stops early
+// when the job is cancelled
+func blockingRun(release <-chan struct{}) RunFunc {
+	return func(ctx context.Context, jobID string, theaterIDs []string, progress func(pipeline.TheaterResult)) pipeline.Report {
+		report := pipeline.Report{StartedAt: time.Now()}
+		for _, id := range theaterIDs {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+
+			result := pipeline.TheaterResult{Metadata: models.ScrapeMetadata{TheaterID: id, Status: "success"}}
+			if err := ctx.Err(); err != nil {
+				result.Err = err
+				result.Metadata.Status = "error"
+				report.Failed++
+			} else {
+				report.Succeeded++
+			}
+			report.Results = append(report.Results, result)
+			progress(result)
+		}
+		return report
+	}
+}
+
+func waitFor(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := m.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("Wait(%s) error = %v", id, err)
+	}
+	return job
+}
+
+func TestManager_RunsJobAndRecordsResults(t *testing.T) {
+	release := make(chan struct{})
+	m := NewManager(blockingRun(release), nil)
+
+	job, coalesced, err := m.Submit([]string{"cst", "academy"})
+	if err != nil || coalesced {
+		t.Fatalf("Submit() = %v, coalesced %v, want new job", err, coalesced)
+	}
+	if job.Status != StatusRunning || len(job.Pending) != 2 {
+		t.Errorf("new job = %+v, want running with 2 pending", job)
+	}
+
+	close(release)
+	job = waitFor(t, m, job.ID)
+
+	if job.Status != StatusSucceeded {
+		t.Errorf("Status = %s, want %s", job.Status, StatusSucceeded)
+	}
+	if len(job.Results) != 2 || len(job.Pending) != 0 {
+		t.Errorf("results/pending = %d/%d, want 2/0", len(job.Results), len(job.Pending))
+	}
+	if job.Summary == nil || job.Summary.Succeeded != 2 || job.FinishedAt == nil {
+		t.Errorf("finished job = %+v, want summary with 2 succeeded", job)
+	}
+}
+
+func TestManager_OverlappingSubmissions(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	m := NewManager(blockingRun(release), nil)
+
+	first, _, err := m.Submit([]string{"cst", "academy"})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// The same theaters in any order join the running job
+	same, coalesced, err := m.Submit([]string{"academy", "cst", "cst"})
+	if err != nil || !coalesced || same.ID != first.ID {
+		t.Errorf("Submit(same) = %s, coalesced %v, %v; want %s coalesced", same.ID, coalesced, err, first.ID)
+	}
+
+	// A partial overlap is rejected
+	_, _, err = m.Submit([]string{"cst", "hollywood"})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.JobID != first.ID || len(conflict.Theaters) != 1 || conflict.Theaters[0] != "cst" {
+		t.Errorf("Submit(overlap) error = %v, want conflict with %s on cst", err, first.ID)
+	}
+
+	// Disjoint theaters run alongside
+	if _, coalesced, err := m.Submit([]string{"hollywood"}); err != nil || coalesced {
+		t.Errorf("Submit(disjoint) = coalesced %v, %v; want new job", coalesced, err)
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m := NewManager(blockingRun(make(chan struct{})), nil)
+
+	job, _, err := m.Submit([]string{"cst"})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if _, err := m.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	job = waitFor(t, m, job.ID)
+
+	if job.Status != StatusCancelled {
+		t.Errorf("Status = %s, want %s", job.Status, StatusCancelled)
+	}
+	if _, err := m.Cancel(job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel() error = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Once finished, the theaters can be scraped again
+	if _, coalesced, err := m.Submit([]string{"cst"}); err != nil || coalesced {
+		t.Errorf("Submit() after cancel = coalesced %v, %v; want new job", coalesced, err)
+	}
+}
@@ -34,18 +34,28 @@ type Movie struct {
 
 // Showtime represents a movie showtime
 type Showtime struct {
-	ID         string  `json:"id"`
-	TheaterID  string  `json:"theater_id"`
-	MovieTitle string  `json:"movie_title"`
-	TMDBID     int     `json:"tmdb_id"`
-	Date       string  `json:"date"`
-	Time       string  `json:"time"`
-	Format     string  `json:"format"`
-	Price      float64 `json:"price,omitempty"`
-	Link       string  `json:"link,omitempty"`
-	Screen     string  `json:"screen,omitempty"`
+	ID          string     `json:"id"`
+	TheaterID   string     `json:"theater_id"`
+	MovieTitle  string     `json:"movie_title"`
+	TMDBID      int        `json:"tmdb_id"`
+	Date        string     `json:"date"`
+	Time        string     `json:"time"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`    // in the theater's time zone; nil when the time is unknown
+	EndsAt      *time.Time `json:"ends_at,omitempty"`      // StartsAt plus the TMDB runtime, when known
+	TimeUnknown bool       `json:"time_unknown,omitempty"` // the listing gave no usable start time
+	Format      string     `json:"format"`
+	Price       float64    `json:"price,omitempty"`
+	Link        string     `json:"link,omitempty"`
+	Screen      string     `json:"screen,omitempty"`
 }
 
+// Scrape statuses recorded in ScrapeMetadata
+const (
+	ScrapeSuccess = "success"
+	ScrapePartial = "partial" // some pages failed; showtimes from them were kept
+	ScrapeError   = "error"
+)
+
 // ScrapeMetadata tracks scraping status
 type ScrapeMetadata struct {
 	LastUpdated      time.Time `json:"last_updated"`
@@ -54,6 +64,23 @@ type ScrapeMetadata struct {
 	ErrorMessage     string    `json:"error_message,omitempty"`
 	MoviesScraped    int       `json:"movies_scraped"`
 	ShowtimesScraped int       `json:"showtimes_scraped"`
+	Attempts         int       `json:"attempts"` // HTTP requests sent, including retries
+	Retries          int       `json:"retries"`
+	PagesFetched     int       `json:"pages_fetched"`
+	FailedURLs       []string  `json:"failed_urls,omitempty"`
+	Warnings         []string  `json:"warnings,omitempty"`
+	Skipped          int       `json:"skipped"` // listings found but not parsed
+	Anomalies        []Anomaly `json:"anomalies,omitempty"`
+}
+
+// Anomaly is a scrape result that looks wrong compared with the theater's
+// recent history, such as a sudden drop in showtimes
+type Anomaly struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	// Baseline is the usual showtime count the run was compared against
+	Baseline int `json:"baseline,omitempty"`
 }
 
 // ScraperResult contains the data returned by a scraper
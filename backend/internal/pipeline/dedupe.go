@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"strings"
+	"unicode"
+
+	"theater-showtimes/internal/models"
+)
+
+// genericFormats are formats scrapers fill in when a page does not say;
+// any other format is more specific
+var genericFormats = map[string]bool{
+	"":        true,
+	"digital": true,
+}
+
+// Dedupe merges showtimes that describe the same screening, such as one
+// listed in both a theater's calendar and list views. Showtimes match on
+// theater, normalized title, date and start time. The first occurrence
+// keeps its position and ID, and each of its fields is filled in or
+// replaced by a more specific value from the others.
+func Dedupe(showtimes []models.Showtime) []models.Showtime {
+	index := make(map[string]int, len(showtimes))
+	deduped := make([]models.Showtime, 0, len(showtimes))
+
+	for _, st := range showtimes {
+		key := dedupeKey(st)
+		if i, exists := index[key]; exists {
+			deduped[i] = merge(deduped[i], st)
+			continue
+		}
+		index[key] = len(deduped)
+		deduped = append(deduped, st)
+	}
+
+	return deduped
+}
+
+// dedupeKey identifies a screening independently of how it was scraped
+func dedupeKey(st models.Showtime) string {
+	return strings.Join([]string{st.TheaterID, normalizeTitle(st.MovieTitle), st.Date, st.Time}, "|")
+}
+
+// normalizeTitle lowercases a title and reduces punctuation and spacing to
+// single spaces, so "Stop Making Sense!" matches "stop making  sense"
+func normalizeTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// merge fills in kept's missing or generic fields from other
+func merge(kept, other models.Showtime) models.Showtime {
+	if kept.ID == "" {
+		kept.ID = other.ID
+	}
+	if kept.TMDBID == 0 {
+		kept.TMDBID = other.TMDBID
+	}
+	if kept.EndsAt == nil {
+		kept.EndsAt = other.EndsAt
+	}
+	if genericFormats[strings.ToLower(kept.Format)] && !genericFormats[strings.ToLower(other.Format)] {
+		kept.Format = other.Format
+	} else if kept.Format == "" {
+		kept.Format = other.Format
+	}
+	if kept.Price == 0 {
+		kept.Price = other.Price
+	}
+	if kept.Link == "" {
+		kept.Link = other.Link
+	}
+	if kept.Screen == "" {
+		kept.Screen = other.Screen
+	}
+	return kept
+}
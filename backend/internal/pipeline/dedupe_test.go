@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"theater-showtimes/internal/models"
+)
+
+func TestDedupe(t *testing.T) {
+	calendar := models.Showtime{ID: "cst-sms-1900", TheaterID: "cst", MovieTitle: "Stop Making Sense", Date: "2026-03-28", Time: "19:00", Format: "digital", Link: "https://cstpdx.com/event/stop-making-sense/"}
+	list := models.Showtime{ID: "cst-sms-list", TheaterID: "cst", MovieTitle: "Stop Making Sense!", Date: "2026-03-28", Time: "19:00", Format: "35mm", Price: 12, Screen: "Main"}
+	late := models.Showtime{ID: "cst-sms-2115", TheaterID: "cst", MovieTitle: "Stop Making Sense", Date: "2026-03-28", Time: "21:15"}
+	elsewhere := models.Showtime{ID: "other-sms", TheaterID: "other", MovieTitle: "Stop Making Sense", Date: "2026-03-28", Time: "19:00"}
+
+	merged := calendar
+	merged.Format = "35mm"
+	merged.Price = 12
+	merged.Screen = "Main"
+
+	tests := []struct {
+		name      string
+		showtimes []models.Showtime
+		want      []models.Showtime
+	}{
+		{"no duplicates", []models.Showtime{calendar, late, elsewhere}, []models.Showtime{calendar, late, elsewhere}},
+		{"merges calendar and list views", []models.Showtime{calendar, late, list}, []models.Showtime{merged, late}},
+		{"keeps the first value when both are specific", []models.Showtime{list, calendar}, []models.Showtime{{
+			ID: "cst-sms-list", TheaterID: "cst", MovieTitle: "Stop Making Sense!", Date: "2026-03-28", Time: "19:00",
+			Format: "35mm", Price: 12, Screen: "Main", Link: calendar.Link,
+		}}},
+		{"exact repeats", []models.Showtime{calendar, calendar}, []models.Showtime{calendar}},
+		{"empty", []models.Showtime{}, []models.Showtime{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Dedupe(tt.showtimes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dedupe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"theater-showtimes/internal/events"
+	"theater-showtimes/internal/health"
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/storage"
+)
+
+// Enricher looks up TMDB data for scraped showtimes. *tmdb.Client satisfies it.
+type Enricher interface {
+	EnrichShowtimes(showtimes []models.Showtime) ([]models.Showtime, map[string]*models.Movie)
+}
+
+// DefaultConcurrency is how many theaters a new pipeline scrapes at once
+const DefaultConcurrency = 4
+
+// Pipeline runs scrape → normalize → enrich → dedupe → persist for a set of
+// theaters. The CLI and the API both use it so they store identical data.
+type Pipeline struct {
+	storage     storage.Store
+	enricher    Enricher
+	logger      *log.Logger
+	concurrency int
+	rateLimits  map[string]time.Duration
+}
+
+// New creates a pipeline that persists into store. enricher may be nil to
+// skip TMDB lookups; logger defaults to log.Default().
+func New(store storage.Store, enricher Enricher, logger *log.Logger) *Pipeline {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Pipeline{
+		storage:     store,
+		enricher:    enricher,
+		logger:      logger,
+		concurrency: DefaultConcurrency,
+	}
+}
+
+// SetConcurrency limits how many theaters are scraped at once; values below
+// 1 scrape one theater at a time. Theaters hosted on the same website are
+// never scraped concurrently, so each scraper's rate limit still holds.
+func (p *Pipeline) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.concurrency = n
+}
+
+// SetRateLimits replaces the request delay of the scrapers with the given
+// IDs; scrapers without an entry keep their own
+func (p *Pipeline) SetRateLimits(limits map[string]time.Duration) {
+	p.rateLimits = limits
+}
+
+// TheaterResult is the outcome of running the pipeline for one theater
+type TheaterResult struct {
+	Theater   models.Theater           `json:"theater"`
+	Metadata  models.ScrapeMetadata    `json:"metadata"`
+	Duration  time.Duration            `json:"duration"`
+	Showtimes []models.Showtime        `json:"-"`
+	Movies    map[string]*models.Movie `json:"-"`
+	Err       error                    `json:"-"`
+}
+
+// Report summarizes a pipeline run across all theaters
+type Report struct {
+	StartedAt      time.Time       `json:"started_at"`
+	FinishedAt     time.Time       `json:"finished_at"`
+	Results        []TheaterResult `json:"results"`
+	TotalShowtimes int             `json:"total_showtimes"`
+	UniqueMovies   int             `json:"unique_movies"`
+	Succeeded      int             `json:"succeeded"`
+	Failed         int             `json:"failed"`
+}
+
+// Metadata returns the scrape metadata of every theater in the report
+func (r Report) Metadata() []models.ScrapeMetadata {
+	metadata := make([]models.ScrapeMetadata, 0, len(r.Results))
+	for _, result := range r.Results {
+		metadata = append(metadata, result.Metadata)
+	}
+	return metadata
+}
+
+// Run executes the pipeline for every scraper, up to the concurrency limit
+// at a time, and returns a summary with results in the order of toRun.
+// A failing theater is recorded in the report and does not stop the others;
+// once ctx is done the remaining theaters are reported as cancelled.
+func (p *Pipeline) Run(ctx context.Context, toRun []scrapers.Scraper, opts scrapers.ScrapeOptions) Report {
+	return p.RunWithProgress(ctx, toRun, opts, nil)
+}
+
+// RunWithProgress is Run, calling progress (if not nil) with each theater's
+// result as soon as that theater finishes. Calls to progress never overlap.
+func (p *Pipeline) RunWithProgress(ctx context.Context, toRun []scrapers.Scraper, opts scrapers.ScrapeOptions, progress func(TheaterResult)) Report {
+	if opts.Logger == nil {
+		opts.Logger = p.logger
+	}
+
+	report := Report{StartedAt: time.Now()}
+	results := make([]TheaterResult, len(toRun))
+
+	workers := make(chan struct{}, max(p.concurrency, 1))
+	sites := newSiteLocks()
+	var progressMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, scraper := range toRun {
+		wg.Add(1)
+		go func(i int, scraper scrapers.Scraper) {
+			defer wg.Done()
+
+			// Wait for the site before taking a worker, so a theater queued
+			// behind another on the same site does not hold a slot idle
+			unlock := sites.lock(siteKey(scraper))
+			defer unlock()
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			results[i] = p.runTheater(ctx, scraper, opts)
+
+			if progress != nil {
+				progressMu.Lock()
+				defer progressMu.Unlock()
+				progress(results[i])
+			}
+		}(i, scraper)
+	}
+	wg.Wait()
+
+	movies := make(map[int]bool)
+	for _, result := range results {
+		if result.Err != nil {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.TotalShowtimes += len(result.Showtimes)
+		for _, movie := range result.Movies {
+			if movie != nil {
+				movies[movie.TMDBID] = true
+			}
+		}
+	}
+
+	report.Results = results
+	report.UniqueMovies = len(movies)
+	report.FinishedAt = time.Now()
+	return report
+}
+
+// siteLocks serializes scrapes of theaters that share a website
+type siteLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newSiteLocks() *siteLocks {
+	return &siteLocks{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock blocks until no other theater on site is being scraped
+func (s *siteLocks) lock(site string) func() {
+	s.mu.Lock()
+	lock, exists := s.locks[site]
+	if !exists {
+		lock = &sync.Mutex{}
+		s.locks[site] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// siteKey identifies the website a scraper fetches from: its theater's
+// host without "www.", or the scraper ID when the theater has no website
+func siteKey(scraper scrapers.Scraper) string {
+	website, err := url.Parse(scraper.GetTheaterInfo().Website)
+	if err != nil || website.Hostname() == "" {
+		return scraper.GetID()
+	}
+	return strings.TrimPrefix(strings.ToLower(website.Hostname()), "www.")
+}
+
+// runTheater scrapes, processes and stores a single theater
+func (p *Pipeline) runTheater(ctx context.Context, scraper scrapers.Scraper, opts scrapers.ScrapeOptions) TheaterResult {
+	started := time.Now()
+	theater := scraper.GetTheaterInfo()
+	opts = withTheater(opts, scraper.GetID())
+	if limit, exists := p.rateLimits[scraper.GetID()]; exists {
+		opts.RateLimit = limit
+	}
+	opts.Stats = &scrapers.FetchStats{}
+	opts.Outcome = &scrapers.Outcome{}
+	opts.Emit(events.Event{Type: events.TheaterStarted})
+
+	result := TheaterResult{
+		Theater: theater,
+		Metadata: models.ScrapeMetadata{
+			LastUpdated: started,
+			TheaterID:   scraper.GetID(),
+			Status:      models.ScrapeSuccess,
+		},
+	}
+
+	fail := func(err error) TheaterResult {
+		recordOutcome(&result.Metadata, opts)
+		result.Err = err
+		result.Metadata.Status = models.ScrapeError
+		result.Metadata.ErrorMessage = err.Error()
+		result.Duration = time.Since(started)
+		p.saveMetadata(result.Metadata)
+		emitFinished(opts, result)
+		return result
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	showtimes, err := scraper.Scrape(ctx, opts)
+	if err != nil {
+		p.logger.Printf("Error scraping %s: %v", scraper.GetID(), err)
+		return fail(err)
+	}
+	if opts.Outcome.AllFailed() && len(showtimes) == 0 {
+		return fail(fmt.Errorf("every page failed: %s", strings.Join(opts.Outcome.FailedURLs(), ", ")))
+	}
+
+	showtimes = Normalize(scraper.GetID(), showtimes)
+
+	var movieData map[string]*models.Movie
+	if p.enricher != nil && len(showtimes) > 0 {
+		showtimes, movieData = p.enricher.EnrichShowtimes(showtimes)
+		emitMatches(opts, movieData)
+		setEndTimes(showtimes, movieData)
+	}
+
+	showtimes = Dedupe(showtimes)
+
+	result.Showtimes = showtimes
+	result.Movies = movieData
+	result.Metadata.ShowtimesScraped = len(showtimes)
+	result.Metadata.MoviesScraped = countMovies(movieData)
+	recordOutcome(&result.Metadata, opts)
+	result.Metadata.Anomalies = p.detectAnomalies(scraper.GetID(), showtimes)
+
+	// Showtimes before the replaced range are already stored as history
+	dates := dateRange(opts)
+	stored := inRange(dates, showtimes)
+
+	// Pages that failed may have listed showtimes we still have stored, so a
+	// partial scrape keeps them instead of replacing the whole date range
+	if len(result.Metadata.FailedURLs) > 0 {
+		result.Metadata.Status = models.ScrapePartial
+		stored, err = p.keepUnscraped(theater.ID, dates, stored)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := p.persist(theater, dates, stored, movieData); err != nil {
+		return fail(err)
+	}
+
+	result.Duration = time.Since(started)
+	p.saveMetadata(result.Metadata)
+	emitFinished(opts, result)
+	return result
+}
+
+// withTheater stamps theaterID on every event emitted through opts
+func withTheater(opts scrapers.ScrapeOptions, theaterID string) scrapers.ScrapeOptions {
+	if opts.Events == nil {
+		return opts
+	}
+
+	sink := opts.Events
+	opts.Events = func(event events.Event) {
+		if event.TheaterID == "" {
+			event.TheaterID = theaterID
+		}
+		sink(event)
+	}
+	return opts
+}
+
+// recordOutcome copies the scrape's request counts and page outcomes into its metadata
+func recordOutcome(metadata *models.ScrapeMetadata, opts scrapers.ScrapeOptions) {
+	metadata.Attempts = opts.Stats.Attempts()
+	metadata.Retries = opts.Stats.Retries()
+	metadata.PagesFetched = opts.Outcome.PagesFetched()
+	metadata.FailedURLs = opts.Outcome.FailedURLs()
+	metadata.Warnings = opts.Outcome.Warnings()
+	metadata.Skipped = opts.Outcome.Skipped()
+}
+
+// emitMatches reports which scraped titles TMDB did and did not recognize
+func emitMatches(opts scrapers.ScrapeOptions, movieData map[string]*models.Movie) {
+	for title, movie := range movieData {
+		if movie == nil {
+			opts.Emit(events.Event{Type: events.TMDBMiss, MovieTitle: title})
+			continue
+		}
+		opts.Emit(events.Event{Type: events.TMDBMatch, MovieTitle: title, TMDBID: movie.TMDBID})
+	}
+}
+
+// emitFinished reports the outcome of a theater
+func emitFinished(opts scrapers.ScrapeOptions, result TheaterResult) {
+	opts.Emit(events.Event{
+		Type:   events.TheaterFinished,
+		Status: result.Metadata.Status,
+		Error:  result.Metadata.ErrorMessage,
+		Data:   result.Metadata,
+	})
+}
+
+// persist stores the theater, replaces its showtimes within the scraped
+// date range and merges its movies
+func (p *Pipeline) persist(theater models.Theater, dates storage.DateRange, showtimes []models.Showtime, movieData map[string]*models.Movie) error {
+	if err := p.storage.UpsertTheater(theater); err != nil {
+		return fmt.Errorf("failed to save theater: %w", err)
+	}
+
+	if err := p.storage.ReplaceShowtimes(theater.ID, dates, showtimes); err != nil {
+		return fmt.Errorf("failed to save showtimes: %w", err)
+	}
+
+	movies := make([]models.Movie, 0, len(movieData))
+	for _, movie := range movieData {
+		if movie != nil {
+			movies = append(movies, *movie)
+		}
+	}
+	if len(movies) > 0 {
+		if err := p.storage.MergeMovies(movies); err != nil {
+			return fmt.Errorf("failed to save movies: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// detectAnomalies compares a theater's scraped showtimes with its recent
+// scrapes; problems loading the history are logged and skip the check
+func (p *Pipeline) detectAnomalies(theaterID string, showtimes []models.Showtime) []models.Anomaly {
+	history, err := p.storage.LoadMetadata(theaterID, health.HistoryWindow)
+	if err != nil {
+		p.logger.Printf("Failed to load scrape history for %s: %v", theaterID, err)
+		return nil
+	}
+
+	anomalies := health.Detect(showtimes, history)
+	for _, anomaly := range anomalies {
+		p.logger.Printf("Anomaly scraping %s: %s", theaterID, anomaly.Message)
+	}
+	return anomalies
+}
+
+// keepUnscraped adds the theater's stored showtimes within dates that the
+// scrape did not return again
+func (p *Pipeline) keepUnscraped(theaterID string, dates storage.DateRange, showtimes []models.Showtime) ([]models.Showtime, error) {
+	existing, err := p.storage.QueryShowtimes(storage.ShowtimeQuery{TheaterID: theaterID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored showtimes: %w", err)
+	}
+
+	scraped := make(map[string]bool, len(showtimes))
+	for _, st := range showtimes {
+		scraped[st.ID] = true
+	}
+
+	kept := append([]models.Showtime(nil), showtimes...)
+	for _, st := range existing {
+		if dates.Contains(st.Date) && !scraped[st.ID] {
+			kept = append(kept, st)
+		}
+	}
+	return kept, nil
+}
+
+// dateRange converts the scrape window into the storage range it replaces
+func dateRange(opts scrapers.ScrapeOptions) storage.DateRange {
+	var dates storage.DateRange
+	if !opts.From.IsZero() {
+		dates.From = opts.From.Format("2006-01-02")
+	} else {
+		// Without a window the range starts today, so past showtimes stay
+		// as history instead of being deleted once the site drops them
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		dates.From = now.In(scrapers.Location).Format("2006-01-02")
+	}
+	if !opts.To.IsZero() {
+		dates.To = opts.To.Format("2006-01-02")
+	}
+	return dates
+}
+
+// inRange returns the showtimes whose dates fall in dates; undated
+// showtimes are kept
+func inRange(dates storage.DateRange, showtimes []models.Showtime) []models.Showtime {
+	kept := make([]models.Showtime, 0, len(showtimes))
+	for _, st := range showtimes {
+		if dates.Contains(st.Date) {
+			kept = append(kept, st)
+		}
+	}
+	return kept
+}
+
+// saveMetadata records the run; failures are logged since the scrape itself succeeded or already failed
+func (p *Pipeline) saveMetadata(metadata models.ScrapeMetadata) {
+	if err := p.storage.SaveMetadata(metadata); err != nil {
+		p.logger.Printf("Failed to save metadata for %s: %v", metadata.TheaterID, err)
+	}
+}
+
+// Normalize cleans up scraper output: it trims fields, collapses whitespace in
+// titles, resolves the start time, fills in a missing theater ID and showtime
+// ID and drops showtimes without a title. A showtime whose date and time do
+// not make a start time is flagged TimeUnknown rather than given a default.
+func Normalize(theaterID string, showtimes []models.Showtime) []models.Showtime {
+	normalized := make([]models.Showtime, 0, len(showtimes))
+
+	for _, st := range showtimes {
+		st.MovieTitle = strings.Join(strings.Fields(st.MovieTitle), " ")
+		if st.MovieTitle == "" {
+			continue
+		}
+
+		st.ID = strings.TrimSpace(st.ID)
+		st.Date = strings.TrimSpace(st.Date)
+		st.Time = strings.TrimSpace(st.Time)
+		st.Format = strings.TrimSpace(st.Format)
+		st.Link = strings.TrimSpace(st.Link)
+		st.Screen = strings.TrimSpace(st.Screen)
+		if st.TheaterID == "" {
+			st.TheaterID = theaterID
+		}
+		st.StartsAt, st.EndsAt = nil, nil
+		if start, err := scrapers.StartTime(st.Date, st.Time); err == nil {
+			st.Time = start.Format("15:04")
+			st.StartsAt = &start
+		}
+		st.TimeUnknown = st.StartsAt == nil
+		if st.TimeUnknown {
+			// Raw text such as "TBA" is not a time; the flag says it all
+			st.Time = ""
+		}
+		if st.ID == "" {
+			st.ID = scrapers.ShowtimeID(st)
+		}
+
+		normalized = append(normalized, st)
+	}
+
+	return normalized
+}
+
+// setEndTimes sets EndsAt on showtimes whose movie has a known runtime
+func setEndTimes(showtimes []models.Showtime, movieData map[string]*models.Movie) {
+	runtimes := make(map[int]int, len(movieData))
+	for _, movie := range movieData {
+		if movie != nil && movie.Runtime > 0 {
+			runtimes[movie.TMDBID] = movie.Runtime
+		}
+	}
+
+	for i, st := range showtimes {
+		runtime, exists := runtimes[st.TMDBID]
+		if st.StartsAt == nil || st.TMDBID == 0 || !exists {
+			continue
+		}
+		end := st.StartsAt.Add(time.Duration(runtime) * time.Minute)
+		showtimes[i].EndsAt = &end
+	}
+}
+
+// countMovies counts the titles that matched a TMDB movie
+func countMovies(movieData map[string]*models.Movie) int {
+	count := 0
+	for _, movie := range movieData {
+		if movie != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,544 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/health"
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/storage"
+)
+
+// fakeScraper returns canned showtimes or an error
+type fakeScraper struct {
+	id        string
+	showtimes []models.Showtime
+	err       error
+}
+
+func (f *fakeScraper) GetTheaterInfo() models.Theater {
+	return models.Theater{ID: f.id, Name: f.id}
+}
+
+func (f *fakeScraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
+	return f.showtimes, f.err
+}
+
+func (f *fakeScraper) GetID() string {
+	return f.id
+}
+
+// pagedScraper returns canned showtimes after reporting page outcomes
+type pagedScraper struct {
+	fakeScraper
+	fetched int
+	failed  []string
+}
+
+func (p *pagedScraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
+	for i := 0; i < p.fetched; i++ {
+		opts.Outcome.PageFetched()
+	}
+	for _, url := range p.failed {
+		opts.Outcome.PageFailed(url)
+	}
+	opts.Skipf("listing without a date")
+	return p.fakeScraper.Scrape(ctx, opts)
+}
+
+// optsScraper records the options it was scraped with
+type optsScraper struct {
+	fakeScraper
+	got scrapers.ScrapeOptions
+}
+
+func (o *optsScraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
+	o.got = opts
+	return o.fakeScraper.Scrape(ctx, opts)
+}
+
+// fakeEnricher assigns TMDB IDs from a fixed title map
+type fakeEnricher struct {
+	movies map[string]*models.Movie
+}
+
+func (f *fakeEnricher) EnrichShowtimes(showtimes []models.Showtime) ([]models.Showtime, map[string]*models.Movie) {
+	found := make(map[string]*models.Movie)
+	for i := range showtimes {
+		movie := f.movies[showtimes[i].MovieTitle]
+		found[showtimes[i].MovieTitle] = movie
+		if movie != nil {
+			showtimes[i].TMDBID = movie.TMDBID
+		}
+	}
+	return showtimes, found
+}
+
+// gatedScraper holds each scrape open until release is closed, recording
+// how many of its group are scraping at the same time
+type gatedScraper struct {
+	fakeScraper
+	website string
+	release <-chan struct{}
+	group   *overlapTracker
+}
+
+func (g *gatedScraper) GetTheaterInfo() models.Theater {
+	return models.Theater{ID: g.id, Name: g.id, Website: g.website}
+}
+
+func (g *gatedScraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
+	if g.group != nil {
+		g.group.enter()
+		defer g.group.leave()
+	}
+
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return g.fakeScraper.Scrape(ctx, opts)
+}
+
+// overlapTracker records the peak number of concurrent callers
+type overlapTracker struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (o *overlapTracker) enter() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.current++
+	o.peak = max(o.peak, o.current)
+}
+
+func (o *overlapTracker) leave() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.current--
+}
+
+// testOptions scrapes as of a fixed day before every fixture's dates, so
+// the replaced range starts before them
+func testOptions() scrapers.ScrapeOptions {
+	return scrapers.ScrapeOptions{Now: time.Date(2026, 1, 1, 12, 0, 0, 0, scrapers.Location)}
+}
+
+func newTestStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	return store
+}
+
+func TestRun_PersistsEnrichedShowtimesAndMovies(t *testing.T) {
+	store := newTestStorage(t)
+	enricher := &fakeEnricher{movies: map[string]*models.Movie{
+		"Alien": {TMDBID: 348, Title: "Alien"},
+	}}
+	scraper := &fakeScraper{id: "cst", showtimes: []models.Showtime{
+		{ID: "cst-1", MovieTitle: "  Alien ", Date: "2026-02-11", Time: "19:00"},
+		{ID: "cst-2", MovieTitle: "Unknown Film", Date: "2026-02-12", Time: "21:00"},
+		{ID: "cst-1", MovieTitle: "Alien", Date: "2026-02-11", Time: "19:00"},
+		{ID: "cst-3", MovieTitle: "   "},
+	}}
+
+	report := New(store, enricher, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	if report.Succeeded != 1 || report.Failed != 0 {
+		t.Fatalf("succeeded/failed = %d/%d, want 1/0", report.Succeeded, report.Failed)
+	}
+	if report.TotalShowtimes != 2 {
+		t.Errorf("TotalShowtimes = %d, want 2", report.TotalShowtimes)
+	}
+	if report.UniqueMovies != 1 {
+		t.Errorf("UniqueMovies = %d, want 1", report.UniqueMovies)
+	}
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	if len(showtimes) != 2 {
+		t.Fatalf("stored %d showtimes, want 2", len(showtimes))
+	}
+	if showtimes[0].MovieTitle != "Alien" || showtimes[0].TMDBID != 348 || showtimes[0].TheaterID != "cst" {
+		t.Errorf("first showtime = %+v, want normalized and enriched Alien", showtimes[0])
+	}
+
+	movies, err := store.LoadMovies()
+	if err != nil {
+		t.Fatalf("LoadMovies() error = %v", err)
+	}
+	if len(movies) != 1 || movies[0].TMDBID != 348 {
+		t.Errorf("movies = %+v, want only Alien", movies)
+	}
+
+	theaters, err := store.LoadTheaters()
+	if err != nil {
+		t.Fatalf("LoadTheaters() error = %v", err)
+	}
+	if len(theaters) != 1 || theaters[0].ID != "cst" {
+		t.Errorf("theaters = %+v, want cst", theaters)
+	}
+}
+
+func TestRun_FailingTheaterDoesNotStopOthers(t *testing.T) {
+	store := newTestStorage(t)
+	broken := &fakeScraper{id: "broken", err: errors.New("site down")}
+	working := &fakeScraper{id: "working", showtimes: []models.Showtime{
+		{ID: "w-1", MovieTitle: "Heat", Date: "2026-02-11", Time: "20:00"},
+	}}
+
+	report := New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{broken, working}, testOptions())
+
+	if report.Succeeded != 1 || report.Failed != 1 {
+		t.Fatalf("succeeded/failed = %d/%d, want 1/1", report.Succeeded, report.Failed)
+	}
+	if got := report.Results[0].Metadata; got.Status != "error" || got.ErrorMessage != "site down" {
+		t.Errorf("broken metadata = %+v, want error status", got)
+	}
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	if len(showtimes) != 1 || showtimes[0].TheaterID != "working" {
+		t.Errorf("showtimes = %+v, want the working theater's showtime", showtimes)
+	}
+}
+
+func TestRun_KeepsOtherTheatersShowtimes(t *testing.T) {
+	store := newTestStorage(t)
+	if err := store.SaveShowtimes([]models.Showtime{
+		{ID: "other-1", TheaterID: "other", MovieTitle: "Vertigo"},
+		{ID: "cst-old", TheaterID: "cst", MovieTitle: "Old Film"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+	scraper := &fakeScraper{id: "cst", showtimes: []models.Showtime{
+		{ID: "cst-new", MovieTitle: "New Film", Date: "2026-02-11", Time: "19:00"},
+	}}
+
+	New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	ids := map[string]bool{}
+	for _, st := range showtimes {
+		ids[st.ID] = true
+	}
+	if len(ids) != 2 || !ids["other-1"] || !ids["cst-new"] {
+		t.Errorf("stored IDs = %v, want other-1 and cst-new", ids)
+	}
+}
+
+func TestRun_FillsMissingShowtimeIDs(t *testing.T) {
+	store := newTestStorage(t)
+	scraper := &fakeScraper{id: "cst", showtimes: []models.Showtime{
+		{MovieTitle: "Heat", Date: "2026-02-11", Time: "20:00"},
+		{ID: "cst-kept", MovieTitle: "Alien", Date: "2026-02-11", Time: "20:00"},
+	}}
+
+	New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	want := scrapers.ShowtimeID(models.Showtime{TheaterID: "cst", MovieTitle: "Heat", Date: "2026-02-11", Time: "20:00"})
+	if len(showtimes) != 2 || showtimes[0].ID != want || showtimes[1].ID != "cst-kept" {
+		t.Errorf("stored showtimes = %+v, want Heat with ID %s and Alien's own ID", showtimes, want)
+	}
+}
+
+func TestRun_AppliesRateLimits(t *testing.T) {
+	limited := &optsScraper{fakeScraper: fakeScraper{id: "limited"}}
+	unlisted := &optsScraper{fakeScraper: fakeScraper{id: "unlisted"}}
+
+	p := New(newTestStorage(t), nil, nil)
+	p.SetRateLimits(map[string]time.Duration{"limited": 5 * time.Second})
+	p.Run(context.Background(), []scrapers.Scraper{limited, unlisted}, testOptions())
+
+	if limited.got.RateLimit != 5*time.Second || unlisted.got.RateLimit != 0 {
+		t.Errorf("rate limits = %s/%s, want 5s for the listed scraper and none for the other",
+			limited.got.RateLimit, unlisted.got.RateLimit)
+	}
+}
+
+func TestRun_ResolvesStartAndEndTimes(t *testing.T) {
+	store := newTestStorage(t)
+	enricher := &fakeEnricher{movies: map[string]*models.Movie{
+		"Alien": {TMDBID: 348, Title: "Alien", Runtime: 117},
+	}}
+	scraper := &fakeScraper{id: "cst", showtimes: []models.Showtime{
+		{ID: "cst-1", MovieTitle: "Alien", Date: "2026-07-04", Time: "7:00 pm"},
+		{ID: "cst-2", MovieTitle: "Heat", Date: "2026-12-04", Time: "21:30"},
+		{ID: "cst-3", MovieTitle: "Stalker", Date: "2026-07-05"},
+		{ID: "cst-4", MovieTitle: "Repo Man", Date: "2026-07-06", Time: "TBA"},
+	}}
+
+	report := New(store, enricher, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	got := report.Results[0].Showtimes
+	if len(got) != 4 {
+		t.Fatalf("got %d showtimes, want 4", len(got))
+	}
+
+	alien := got[0]
+	if alien.Time != "19:00" || alien.StartsAt == nil || alien.StartsAt.Format(time.RFC3339) != "2026-07-04T19:00:00-07:00" {
+		t.Errorf("Alien time/starts_at = %q/%v, want 19:00 Pacific daylight time", alien.Time, alien.StartsAt)
+	}
+	if alien.EndsAt == nil || alien.EndsAt.Format(time.RFC3339) != "2026-07-04T20:57:00-07:00" {
+		t.Errorf("Alien ends_at = %v, want start plus the 117 minute runtime", alien.EndsAt)
+	}
+
+	heat := got[1]
+	if heat.StartsAt == nil || heat.StartsAt.Format(time.RFC3339) != "2026-12-04T21:30:00-08:00" || heat.EndsAt != nil {
+		t.Errorf("Heat starts_at/ends_at = %v/%v, want Pacific standard time and no end without a runtime", heat.StartsAt, heat.EndsAt)
+	}
+
+	stalker := got[2]
+	if !stalker.TimeUnknown || stalker.StartsAt != nil || stalker.Time != "" {
+		t.Errorf("Stalker = %+v, want an unknown time rather than a default", stalker)
+	}
+
+	repoMan := got[3]
+	if !repoMan.TimeUnknown || repoMan.StartsAt != nil || repoMan.Time != "" {
+		t.Errorf("Repo Man = %+v, want an unknown time with the unparsable text cleared", repoMan)
+	}
+
+	stored, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	if stored[0].StartsAt == nil || !stored[0].StartsAt.Equal(*alien.StartsAt) {
+		t.Errorf("stored starts_at = %v, want %v", stored[0].StartsAt, alien.StartsAt)
+	}
+}
+
+func TestRun_KeepsPastShowtimes(t *testing.T) {
+	store := newTestStorage(t)
+	if err := store.SaveShowtimes([]models.Showtime{
+		{ID: "cst-past", TheaterID: "cst", MovieTitle: "Alien", Date: "2026-01-10"},
+		{ID: "cst-dropped", TheaterID: "cst", MovieTitle: "Heat", Date: "2026-02-01"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+	scraper := &fakeScraper{id: "cst", showtimes: []models.Showtime{
+		{ID: "cst-past", MovieTitle: "Alien", Date: "2026-01-10"},
+		{ID: "cst-new", MovieTitle: "Stalker", Date: "2026-02-05"},
+	}}
+
+	// No window, as the API and scheduler scrape
+	opts := scrapers.ScrapeOptions{Now: time.Date(2026, 1, 20, 12, 0, 0, 0, scrapers.Location)}
+	New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{scraper}, opts)
+
+	stored, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	ids := make([]string, 0, len(stored))
+	for _, st := range stored {
+		ids = append(ids, st.ID)
+	}
+	sort.Strings(ids)
+	if strings.Join(ids, ",") != "cst-new,cst-past" {
+		t.Errorf("stored IDs = %v, want the past showtime kept once and the upcoming ones replaced", ids)
+	}
+}
+
+func TestRun_PartialScrapeKeepsUnscrapedShowtimes(t *testing.T) {
+	store := newTestStorage(t)
+	if err := store.SaveShowtimes([]models.Showtime{
+		{ID: "cst-feb", TheaterID: "cst", MovieTitle: "Alien", Date: "2026-02-11"},
+		{ID: "cst-mar", TheaterID: "cst", MovieTitle: "Heat", Date: "2026-03-11"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+	scraper := &pagedScraper{
+		fakeScraper: fakeScraper{id: "cst", showtimes: []models.Showtime{
+			{ID: "cst-feb", MovieTitle: "Aliens", Date: "2026-02-11", Time: "19:00"},
+		}},
+		fetched: 1,
+		failed:  []string{"https://example.com/march"},
+	}
+
+	report := New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	got := report.Results[0].Metadata
+	if got.Status != models.ScrapePartial || got.PagesFetched != 1 || got.Skipped != 1 || got.ShowtimesScraped != 1 {
+		t.Errorf("metadata = %+v, want partial with 1 page, 1 skip and 1 showtime", got)
+	}
+	if len(got.FailedURLs) != 1 || got.FailedURLs[0] != "https://example.com/march" {
+		t.Errorf("FailedURLs = %v, want the march page", got.FailedURLs)
+	}
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	titles := map[string]string{}
+	for _, st := range showtimes {
+		titles[st.ID] = st.MovieTitle
+	}
+	if len(titles) != 2 || titles["cst-feb"] != "Aliens" || titles["cst-mar"] != "Heat" {
+		t.Errorf("stored = %v, want the rescraped cst-feb and the kept cst-mar", titles)
+	}
+}
+
+func TestRun_EveryPageFailedIsAnError(t *testing.T) {
+	store := newTestStorage(t)
+	scraper := &pagedScraper{
+		fakeScraper: fakeScraper{id: "cst"},
+		failed:      []string{"https://example.com/feb", "https://example.com/march"},
+	}
+
+	report := New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{scraper}, testOptions())
+
+	if report.Failed != 1 {
+		t.Fatalf("failed = %d, want 1", report.Failed)
+	}
+	if got := report.Results[0].Metadata; got.Status != models.ScrapeError || len(got.FailedURLs) != 2 {
+		t.Errorf("metadata = %+v, want error status listing both pages", got)
+	}
+}
+
+func TestRun_FlagsShowtimeDropAgainstHistory(t *testing.T) {
+	store := newTestStorage(t)
+	for i := 0; i < 3; i++ {
+		if err := store.SaveMetadata(models.ScrapeMetadata{TheaterID: "cst", Status: models.ScrapeSuccess, ShowtimesScraped: 40}); err != nil {
+			t.Fatalf("SaveMetadata() error = %v", err)
+		}
+	}
+
+	report := New(store, nil, nil).Run(context.Background(), []scrapers.Scraper{&fakeScraper{id: "cst"}}, testOptions())
+
+	anomalies := report.Results[0].Metadata.Anomalies
+	if len(anomalies) != 1 || anomalies[0].Kind != health.ZeroShowtimes {
+		t.Errorf("anomalies = %+v, want zero_showtimes", anomalies)
+	}
+
+	history, err := store.LoadMetadata("cst", 1)
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	if len(history) != 1 || len(history[0].Anomalies) != 1 {
+		t.Errorf("stored metadata = %+v, want the anomaly recorded", history)
+	}
+}
+
+func TestRun_CancelledContextSkipsScraping(t *testing.T) {
+	store := newTestStorage(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := New(store, nil, nil).Run(ctx, []scrapers.Scraper{&fakeScraper{id: "cst"}}, testOptions())
+
+	if report.Failed != 1 || !errors.Is(report.Results[0].Err, context.Canceled) {
+		t.Errorf("result = %+v, want cancelled failure", report.Results[0])
+	}
+}
+
+func TestRun_SlowTheaterDoesNotBlockOthers(t *testing.T) {
+	store := newTestStorage(t)
+	release := make(chan struct{})
+	slow := &gatedScraper{fakeScraper: fakeScraper{id: "slow", showtimes: []models.Showtime{
+		{ID: "s-1", MovieTitle: "Solaris", Date: "2026-02-11", Time: "19:00"},
+	}}, website: "https://slow.example", release: release}
+	fast := &fakeScraper{id: "fast", showtimes: []models.Showtime{
+		{ID: "f-1", MovieTitle: "Speed", Date: "2026-02-11", Time: "20:00"},
+	}}
+	broken := &fakeScraper{id: "broken", err: errors.New("site down")}
+
+	p := New(store, nil, nil)
+	p.SetConcurrency(2)
+
+	// Release the slow theater only once both others have finished
+	var finished []string
+	progress := func(result TheaterResult) {
+		finished = append(finished, result.Metadata.TheaterID)
+		if len(finished) == 2 {
+			close(release)
+		}
+	}
+
+	done := make(chan Report)
+	go func() {
+		done <- p.RunWithProgress(context.Background(), []scrapers.Scraper{slow, fast, broken}, testOptions(), progress)
+	}()
+
+	var report Report
+	select {
+	case report = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() blocked behind the slow theater")
+	}
+
+	if len(finished) != 3 || finished[2] != "slow" {
+		t.Errorf("finish order = %v, want slow last", finished)
+	}
+	if report.Succeeded != 2 || report.Failed != 1 || report.TotalShowtimes != 2 {
+		t.Errorf("succeeded/failed/showtimes = %d/%d/%d, want 2/1/2", report.Succeeded, report.Failed, report.TotalShowtimes)
+	}
+	for i, want := range []string{"slow", "fast", "broken"} {
+		if got := report.Results[i].Metadata.TheaterID; got != want {
+			t.Errorf("Results[%d] = %s, want %s (input order)", i, got, want)
+		}
+	}
+}
+
+func TestRun_LimitsConcurrencyAndSerializesSites(t *testing.T) {
+	release := make(chan struct{})
+	all := &overlapTracker{}
+	sameSite := &overlapTracker{}
+
+	var toRun []scrapers.Scraper
+	for i := 0; i < 6; i++ {
+		toRun = append(toRun, &gatedScraper{
+			fakeScraper: fakeScraper{id: fmt.Sprintf("theater-%d", i)},
+			website:     fmt.Sprintf("https://theater-%d.example", i),
+			release:     release,
+			group:       all,
+		})
+	}
+	for _, website := range []string{"https://shared.example/a", "https://www.shared.example/b"} {
+		toRun = append(toRun, &gatedScraper{
+			fakeScraper: fakeScraper{id: website},
+			website:     website,
+			release:     release,
+			group:       sameSite,
+		})
+	}
+
+	p := New(newTestStorage(t), nil, nil)
+	p.SetConcurrency(3)
+
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	report := p.Run(context.Background(), toRun, testOptions())
+
+	if report.Succeeded != len(toRun) {
+		t.Fatalf("succeeded = %d, want %d", report.Succeeded, len(toRun))
+	}
+	if all.peak < 2 || all.peak > 3 {
+		t.Errorf("peak concurrency = %d, want 2 or 3 with a limit of 3", all.peak)
+	}
+	if sameSite.peak != 1 {
+		t.Errorf("peak concurrency on one site = %d, want 1", sameSite.peak)
+	}
+}
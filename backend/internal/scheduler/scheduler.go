@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"theater-showtimes/internal/jobs"
+	"theater-showtimes/internal/models"
+)
+
+// Submitter starts scrape jobs. *jobs.Manager satisfies it.
+type Submitter interface {
+	Submit(theaterIDs []string) (jobs.Job, bool, error)
+}
+
+// History reports past scrapes so missed runs can be caught up after a
+// restart. storage.Store satisfies it.
+type History interface {
+	LoadMetadata(theaterID string, limit int) ([]models.ScrapeMetadata, error)
+}
+
+// State is the schedule of one theater, as exposed by the API
+type State struct {
+	TheaterID string     `json:"theater_id"`
+	Spec      string     `json:"spec"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	NextRun   *time.Time `json:"next_run,omitempty"`
+	LastJobID string     `json:"last_job_id,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	CatchUp   bool       `json:"catch_up,omitempty"` // the next run makes up for a missed one
+}
+
+// entry is a theater's parsed schedule and run state, guarded by Scheduler.mu
+type entry struct {
+	State
+	schedule cron.Schedule
+}
+
+// Scheduler scrapes each registered theater on its own cron schedule.
+// Runs are submitted as scrape jobs, so a scheduled run and an on-demand
+// run for the same theater never overlap. Each run is delayed by a random
+// jitter, and a run missed while the server was down is made up shortly
+// after start.
+type Scheduler struct {
+	submit  Submitter
+	history History
+	jitter  time.Duration
+	logger  *log.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	started bool
+}
+
+// New creates a scheduler that submits runs to submit and reads past runs
+// from history. jitter bounds the random delay added to each run; logger
+// defaults to log.Default().
+func New(submit Submitter, history History, jitter time.Duration, logger *log.Logger) *Scheduler {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Scheduler{
+		submit:  submit,
+		history: history,
+		jitter:  jitter,
+		logger:  logger,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Add schedules theaterID with a standard five-field cron expression or a
+// descriptor such as "@every 6h". Prefix the spec with CRON_TZ=<zone> to
+// evaluate it outside the server's local time zone.
+func (s *Scheduler) Add(theaterID, spec string) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for %s: %w", spec, theaterID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("cannot add %s: scheduler already started", theaterID)
+	}
+	s.entries[theaterID] = &entry{
+		State:    State{TheaterID: theaterID, Spec: spec},
+		schedule: schedule,
+	}
+	return nil
+}
+
+// Start runs every schedule in the background until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	now := time.Now()
+	for _, e := range s.entries {
+		e.LastRun = s.lastRun(e.TheaterID)
+		e.CatchUp = Missed(e.schedule, e.LastRun, now)
+		go s.loop(ctx, e)
+	}
+}
+
+// States returns every theater's schedule, ordered by theater ID
+func (s *Scheduler) States() []State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]State, 0, len(s.entries))
+	for _, e := range s.entries {
+		states = append(states, e.State)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].TheaterID < states[j].TheaterID
+	})
+	return states
+}
+
+// loop waits for each of the entry's run times and submits a job
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		s.mu.Lock()
+		next := time.Now()
+		if !e.CatchUp {
+			next = e.schedule.Next(next)
+		}
+		next = next.Add(s.randomJitter())
+		e.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.fire(e)
+	}
+}
+
+// fire submits a scrape job for the entry's theater and records the outcome
+func (s *Scheduler) fire(e *entry) {
+	job, _, err := s.submit.Submit([]string{e.TheaterID})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A failed submit still clears CatchUp so the loop waits for the next
+	// slot, but LastRun keeps pointing at the last run that really started
+	e.CatchUp = false
+	if err != nil {
+		e.LastError = err.Error()
+		s.logger.Printf("Scheduled scrape of %s not started: %v", e.TheaterID, err)
+		return
+	}
+	now := time.Now()
+	e.LastRun = &now
+	e.LastError = ""
+	e.LastJobID = job.ID
+	s.logger.Printf("Scheduled scrape of %s started as job %s", e.TheaterID, job.ID)
+}
+
+// lastRun returns when theaterID was last scraped, or nil if it never was
+func (s *Scheduler) lastRun(theaterID string) *time.Time {
+	if s.history == nil {
+		return nil
+	}
+
+	records, err := s.history.LoadMetadata(theaterID, 1)
+	if err != nil {
+		s.logger.Printf("Failed to load last scrape of %s: %v", theaterID, err)
+		return nil
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return &records[0].LastUpdated
+}
+
+// randomJitter returns a random delay in [0, jitter)
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
+
+// Missed reports whether a run was due between lastRun and now. A theater
+// that has never been scraped always counts as missed.
+func Missed(schedule cron.Schedule, lastRun *time.Time, now time.Time) bool {
+	if lastRun == nil {
+		return true
+	}
+	return !schedule.Next(*lastRun).After(now)
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"theater-showtimes/internal/jobs"
+	"theater-showtimes/internal/models"
+)
+
+// fakeSubmitter records submitted theaters, failing every submit with err if set
+type fakeSubmitter struct {
+	submitted chan string
+	err       error
+}
+
+func (f *fakeSubmitter) Submit(theaterIDs []string) (jobs.Job, bool, error) {
+	f.submitted <- theaterIDs[0]
+	if f.err != nil {
+		return jobs.Job{}, false, f.err
+	}
+	return jobs.Job{ID: "job-" + theaterIDs[0]}, false, nil
+}
+
+// fakeHistory returns a fixed last run per theater
+type fakeHistory map[string]time.Time
+
+func (f fakeHistory) LoadMetadata(theaterID string, limit int) ([]models.ScrapeMetadata, error) {
+	last, exists := f[theaterID]
+	if !exists {
+		return nil, nil
+	}
+	return []models.ScrapeMetadata{{TheaterID: theaterID, LastUpdated: last}}, nil
+}
+
+func TestMissed(t *testing.T) {
+	everySixHours, err := cron.ParseStandard("0 */6 * * *")
+	if err != nil {
+		t.Fatalf("ParseStandard() error = %v", err)
+	}
+	now := time.Date(2026, 3, 1, 13, 0, 0, 0, time.Local)
+	at := func(hour, minute int) *time.Time {
+		t := time.Date(2026, 3, 1, hour, minute, 0, 0, time.Local)
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		lastRun *time.Time
+		want    bool
+	}{
+		{"never run", nil, true},
+		{"ran after the last slot", at(12, 5), false},
+		{"ran before the last slot", at(11, 59), true},
+		{"ran exactly at the last slot", at(12, 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := Missed(everySixHours, tt.lastRun, now); got != tt.want {
+			t.Errorf("%s: Missed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScheduler_CatchesUpMissedRuns(t *testing.T) {
+	submitter := &fakeSubmitter{submitted: make(chan string, 10)}
+	history := fakeHistory{
+		"fresh": time.Now(),
+		"stale": time.Now().Add(-48 * time.Hour),
+	}
+
+	s := New(submitter, history, 0, nil)
+	for _, id := range []string{"fresh", "stale", "new"} {
+		if err := s.Add(id, "@daily"); err != nil {
+			t.Fatalf("Add(%s) error = %v", id, err)
+		}
+	}
+	if err := s.Add("bad", "every tuesday"); err == nil {
+		t.Error("Add() with an invalid spec succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	got := make(map[string]bool)
+	for len(got) < 2 {
+		select {
+		case id := <-submitter.submitted:
+			got[id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("caught up %v, want stale and new", got)
+		}
+	}
+	if !got["stale"] || !got["new"] {
+		t.Errorf("caught up %v, want stale and new", got)
+	}
+
+	select {
+	case id := <-submitter.submitted:
+		t.Errorf("unexpected run of %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for _, state := range s.States() {
+		if state.NextRun == nil {
+			t.Errorf("%s has no next run", state.TheaterID)
+		}
+		if state.TheaterID != "fresh" && state.LastJobID != "job-"+state.TheaterID {
+			t.Errorf("%s last job = %q, want job-%s", state.TheaterID, state.LastJobID, state.TheaterID)
+		}
+	}
+}
+
+func TestScheduler_FailedSubmitIsNotARun(t *testing.T) {
+	lastRun := time.Now().Add(-48 * time.Hour)
+	submitter := &fakeSubmitter{submitted: make(chan string, 1), err: errors.New("job queue full")}
+
+	s := New(submitter, nil, 0, nil)
+	if err := s.Add("stale", "@daily"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	e := s.entries["stale"]
+	e.LastRun = &lastRun
+	e.CatchUp = true
+
+	s.fire(e)
+
+	state := s.States()[0]
+	if state.LastRun == nil || !state.LastRun.Equal(lastRun) {
+		t.Errorf("LastRun = %v, want the previous run %v", state.LastRun, lastRun)
+	}
+	if state.LastError != "job queue full" {
+		t.Errorf("LastError = %q, want job queue full", state.LastError)
+	}
+	if state.LastJobID != "" {
+		t.Errorf("LastJobID = %q, want none", state.LastJobID)
+	}
+	if state.CatchUp {
+		t.Error("CatchUp still set, the loop would retry immediately")
+	}
+}
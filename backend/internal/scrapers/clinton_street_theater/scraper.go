@@ -1,6 +1,7 @@
 package clinton_street_theater
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -10,11 +11,15 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
 )
 
 // Scraper implements the scraper for Clinton Street Theater
 type Scraper struct {
 	theater models.Theater
+
+	// Retry controls how failed page fetches are retried
+	Retry scrapers.RetryPolicy
 }
 
 // NewScraper creates a new Clinton Street Theater scraper
@@ -29,6 +34,14 @@ func NewScraper() *Scraper {
 			Website: "https://cstpdx.com",
 			Phone:   "(971) 808-3331",
 		},
+		// Month pages are the whole schedule, so try a little harder and
+		// back off gently on a small nonprofit's server
+		Retry: scrapers.RetryPolicy{
+			MaxAttempts:    4,
+			BaseDelay:      2 * time.Second,
+			MaxDelay:       time.Minute,
+			AttemptTimeout: scrapers.DefaultRetryPolicy.AttemptTimeout,
+		},
 	}
 }
 
@@ -43,69 +56,116 @@ func (s *Scraper) GetID() string {
 }
 
 // Scrape performs the actual scraping
-func (s *Scraper) Scrape() ([]models.Showtime, error) {
+func (s *Scraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
 	showtimes := []models.Showtime{}
 
-	c := colly.NewCollector(
+	// page is the month being visited; visits are synchronous, so callbacks
+	// always see the month of the page they are parsing
+	var page time.Time
+
+	c := scrapers.NewCollector(ctx, opts, s.Retry,
 		colly.AllowedDomains("cstpdx.com", "www.cstpdx.com"),
 		colly.UserAgent("Mozilla/5.0 (compatible; TheaterShowtimesBot/1.0)"),
 	)
 
 	// Rate limiting
-	c.Limit(&colly.LimitRule{
+	if err := opts.Limit(c, &colly.LimitRule{
 		DomainGlob:  "*cstpdx.com*",
 		RandomDelay: 2 * time.Second,
-	})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set rate limit: %w", err)
+	}
 
 	// Extract event data from calendar month view
 	c.OnHTML("article.tribe-events-calendar-month__calendar-event", func(eventElem *colly.HTMLElement) {
-		showtime := s.extractCalendarShowtime(eventElem)
-		if showtime != nil {
+		showtime := s.extractCalendarShowtime(eventElem, opts)
+		if showtime != nil && opts.InWindow(showtime.Date) {
 			showtimes = append(showtimes, *showtime)
+			opts.EmitShowtime(*showtime)
 		}
 	})
 
 	// Fallback: Extract from list view if calendar doesn't work
 	c.OnHTML(".tribe-events-calendar-list__event", func(e *colly.HTMLElement) {
-		showtime := s.extractShowtime(e)
-		if showtime != nil {
+		showtime := s.extractShowtime(e, opts, page)
+		if showtime != nil && opts.InWindow(showtime.Date) {
 			showtimes = append(showtimes, *showtime)
+			opts.EmitShowtime(*showtime)
 		}
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
-		fmt.Printf("Error scraping %s: %v\n", r.Request.URL, err)
+		opts.Logf("Error scraping %s: %v", r.Request.URL, err)
 	})
 
-	// Scrape current month and next 2 months for complete schedule
-	now := time.Now()
-	monthsToScrape := 3
-	
-	for i := 0; i < monthsToScrape; i++ {
-		targetDate := now.AddDate(0, i, 0)
-		monthURL := fmt.Sprintf("%s/schedule/month/%d-%02d/", 
-			s.theater.Website, 
-			targetDate.Year(), 
-			targetDate.Month())
-		
-		fmt.Printf("Scraping month: %s\n", targetDate.Format("January 2006"))
+	// Scrape every month in the requested window; by default the current
+	// month and next 2 months for a complete schedule
+	months := s.monthsToScrape(opts)
+	var failed int
+	var lastErr error
+	for _, month := range months {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		monthURL := fmt.Sprintf("%s/schedule/month/%d-%02d/",
+			s.theater.Website,
+			month.Year(),
+			month.Month())
+
+		opts.Logf("Scraping month: %s", month.Format("January 2006"))
+		page = month
 		err := c.Visit(monthURL)
 		if err != nil {
-			fmt.Printf("Warning: failed to scrape %s: %v\n", monthURL, err)
+			failed++
+			lastErr = err
+			opts.Outcome.PageFailed(monthURL)
+			opts.Warnf("failed to scrape %s: %v", monthURL, err)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if failed > 0 && failed == len(months) {
+		return nil, fmt.Errorf("failed to scrape all %d month pages: %w", failed, lastErr)
+	}
+
 	return showtimes, nil
 }
 
-// extractShowtime parses an event element and returns a Showtime if it's a movie screening
-func (s *Scraper) extractShowtime(e *colly.HTMLElement) *models.Showtime {
+// monthsToScrape returns the first day of every month covered by the scrape window
+func (s *Scraper) monthsToScrape(opts scrapers.ScrapeOptions) []time.Time {
+	start := opts.From
+	if start.IsZero() {
+		start = time.Now()
+	}
+	end := opts.To
+	if end.IsZero() {
+		end = start.AddDate(0, 2, 0)
+	}
+
+	first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	months := []time.Time{}
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	return months
+}
+
+// extractShowtime parses an event element and returns a Showtime if it's a movie screening.
+// page is the month being scraped, which places dates printed without a year.
+func (s *Scraper) extractShowtime(e *colly.HTMLElement, opts scrapers.ScrapeOptions, page time.Time) *models.Showtime {
 	// Extract movie title (clean up year and special tags)
 	rawTitle := e.ChildText(".tribe-events-calendar-list__event-title-link")
 	if rawTitle == "" {
 		rawTitle = e.ChildText("h3")
 	}
 	if rawTitle == "" {
+		opts.Skipf("list event without a title on %s", e.Request.URL)
 		return nil
 	}
 
@@ -122,8 +182,11 @@ func (s *Scraper) extractShowtime(e *colly.HTMLElement) *models.Showtime {
 		dateTimeStr = e.ChildText("time")
 	}
 	
-	date, showTime := s.parseDateTime(dateTimeStr)
-	if date == "" || showTime == "" {
+	// A listing without a usable time is kept; the pipeline marks it as
+	// time unknown
+	date, showTime := s.parseDateTime(dateTimeStr, opts.Dates(page))
+	if date == "" {
+		opts.Skipf("%q has unparsable date %q", movieTitle, dateTimeStr)
 		return nil
 	}
 
@@ -140,11 +203,7 @@ func (s *Scraper) extractShowtime(e *colly.HTMLElement) *models.Showtime {
 	}
 	price := s.parsePrice(priceStr)
 
-	// Generate unique ID
-	id := fmt.Sprintf("%s-%s-%s", s.theater.ID, s.sanitizeForID(movieTitle), strings.ReplaceAll(date+showTime, ":", ""))
-
-	return &models.Showtime{
-		ID:         id,
+	showtime := &models.Showtime{
 		TheaterID:  s.theater.ID,
 		MovieTitle: movieTitle,
 		Date:       date,
@@ -153,16 +212,19 @@ func (s *Scraper) extractShowtime(e *colly.HTMLElement) *models.Showtime {
 		Price:      price,
 		Link:       eventLink,
 	}
+	showtime.ID = scrapers.ShowtimeID(*showtime)
+	return showtime
 }
 
 // extractCalendarShowtime parses an event from the calendar view
-func (s *Scraper) extractCalendarShowtime(e *colly.HTMLElement) *models.Showtime {
+func (s *Scraper) extractCalendarShowtime(e *colly.HTMLElement, opts scrapers.ScrapeOptions) *models.Showtime {
 	// Extract the event link and title
 	rawTitle := e.ChildText(".tribe-events-calendar-month__calendar-event-title a")
 	if rawTitle == "" {
 		rawTitle = e.ChildText("a")
 	}
 	if rawTitle == "" {
+		opts.Skipf("calendar event without a title on %s", e.Request.URL)
 		return nil
 	}
 
@@ -174,10 +236,8 @@ func (s *Scraper) extractCalendarShowtime(e *colly.HTMLElement) *models.Showtime
 	}
 
 	// Extract time from the time element's datetime attribute
-	timeStr := e.ChildAttr(".tribe-events-calendar-month__calendar-event-datetime time", "datetime")
-	if timeStr == "" {
-		timeStr = "19:00" // Default to 7 PM
-	}
+	// All-day events have none and are left without a time
+	timeStr, _ := scrapers.ParseClock(e.ChildAttr(".tribe-events-calendar-month__calendar-event-datetime time", "datetime"))
 
 	// Extract date from parent day cell
 	// The parent .tribe-events-calendar-month__day has the date in a time element
@@ -191,6 +251,7 @@ func (s *Scraper) extractCalendarShowtime(e *colly.HTMLElement) *models.Showtime
 	})
 
 	if dateStr == "" {
+		opts.Skipf("%q is not inside a dated calendar day", movieTitle)
 		return nil
 	}
 
@@ -200,19 +261,18 @@ func (s *Scraper) extractCalendarShowtime(e *colly.HTMLElement) *models.Showtime
 		eventLink = e.ChildAttr("a", "href")
 	}
 
-	// Generate unique ID
-	id := fmt.Sprintf("%s-%s-%s", s.theater.ID, s.sanitizeForID(movieTitle), strings.ReplaceAll(dateStr+timeStr, ":", ""))
-
-	return &models.Showtime{
-		ID:         id,
+	// The calendar shows no prices; the pipeline's dedupe takes the price
+	// from the list view when a page has both
+	showtime := &models.Showtime{
 		TheaterID:  s.theater.ID,
 		MovieTitle: movieTitle,
 		Date:       dateStr,
 		Time:       timeStr,
 		Format:     "digital",
-		Price:      10.0, // Default price for CST
 		Link:       eventLink,
 	}
+	showtime.ID = scrapers.ShowtimeID(*showtime)
+	return showtime
 }
 
 // cleanMovieTitle removes year annotations and special tags from the title
@@ -272,111 +332,27 @@ func (s *Scraper) isNonMovieEvent(title, rawTitle string) bool {
 	return false
 }
 
-// parseDateTime extracts date and time from strings like "Wednesday, February 11 @ 7:00 PM"
-func (s *Scraper) parseDateTime(dateTimeStr string) (string, string) {
+// parseDateTime extracts date and time from strings like "Wednesday, February 11 @ 7:00 PM",
+// resolving the year with dates. All-day events such as "February 11" have
+// no "@" and return an empty time.
+func (s *Scraper) parseDateTime(dateTimeStr string, dates scrapers.DateResolver) (string, string) {
 	if dateTimeStr == "" {
 		return "", ""
 	}
 
 	// Pattern: "Day, Month DD @ HH:MM AM/PM"
-	parts := strings.Split(dateTimeStr, "@")
-	if len(parts) != 2 {
-		return "", ""
-	}
-
-	datePart := strings.TrimSpace(parts[0])
-	timePart := strings.TrimSpace(parts[1])
-
-	// Parse the date (e.g., "Wednesday, February 11")
-	date := s.parseDate(datePart)
-	
-	// Parse the time (e.g., "7:00 PM")
-	showTime := s.parseTime(timePart)
-
-	return date, showTime
-}
+	datePart, timePart, _ := strings.Cut(dateTimeStr, "@")
 
-// parseDate converts "Wednesday, February 11" to "2026-02-11" format
-func (s *Scraper) parseDate(datePart string) string {
-	// Remove day of week
-	parts := strings.Split(datePart, ",")
-	if len(parts) < 2 {
-		return ""
-	}
-
-	monthDay := strings.TrimSpace(parts[1])
-	
-	// Parse month and day
-	fields := strings.Fields(monthDay)
-	if len(fields) < 2 {
-		return ""
-	}
-
-	monthName := fields[0]
-	dayStr := fields[1]
-
-	// Map month name to number
-	monthMap := map[string]string{
-		"January": "01", "February": "02", "March": "03", "April": "04",
-		"May": "05", "June": "06", "July": "07", "August": "08",
-		"September": "09", "October": "10", "November": "11", "December": "12",
-	}
-
-	monthNum, exists := monthMap[monthName]
-	if !exists {
-		return ""
-	}
-
-	// Pad day with leading zero if needed
-	day := dayStr
-	if len(day) == 1 {
-		day = "0" + day
-	}
-
-	// Assume current year (could be improved with logic to handle year boundaries)
-	year := time.Now().Year()
-
-	return fmt.Sprintf("%d-%s-%s", year, monthNum, day)
-}
-
-// parseTime converts "7:00 PM" to "19:00" format
-func (s *Scraper) parseTime(timePart string) string {
-	timePart = strings.TrimSpace(timePart)
-	
-	// Check for AM/PM
-	isPM := strings.HasSuffix(strings.ToUpper(timePart), "PM")
-	isAM := strings.HasSuffix(strings.ToUpper(timePart), "AM")
-	
-	if !isAM && !isPM {
-		return timePart // Return as-is if no AM/PM
-	}
-
-	// Remove AM/PM
-	timePart = strings.TrimSuffix(strings.TrimSuffix(timePart, " PM"), " AM")
-	timePart = strings.TrimSuffix(strings.TrimSuffix(timePart, " pm"), " am")
-	timePart = strings.TrimSpace(timePart)
-
-	// Split hour and minute
-	parts := strings.Split(timePart, ":")
-	if len(parts) != 2 {
-		return ""
-	}
-
-	hour, err := strconv.Atoi(parts[0])
+	date, err := dates.Resolve(datePart)
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
-	minute := parts[1]
+	// Parse the start time (e.g., "7:00 PM", or "7:00 PM - 9:30 PM" for a range)
+	start, _, _ := strings.Cut(timePart, "-")
+	showTime, _ := scrapers.ParseClock(start)
 
-	// Convert to 24-hour format
-	if isPM && hour != 12 {
-		hour += 12
-	} else if isAM && hour == 12 {
-		hour = 0
-	}
-
-	return fmt.Sprintf("%02d:%s", hour, minute)
+	return date, showTime
 }
 
 // parsePrice extracts price from strings like "$10" or "[$10]"
@@ -396,18 +372,3 @@ func (s *Scraper) parsePrice(priceStr string) float64 {
 
 	return price
 }
-
-// sanitizeForID creates a URL-safe version of the title for IDs
-func (s *Scraper) sanitizeForID(title string) string {
-	// Convert to lowercase
-	safe := strings.ToLower(title)
-	
-	// Replace spaces and special chars with hyphens
-	re := regexp.MustCompile(`[^a-z0-9]+`)
-	safe = re.ReplaceAllString(safe, "-")
-	
-	// Remove leading/trailing hyphens
-	safe = strings.Trim(safe, "-")
-	
-	return safe
-}
@@ -0,0 +1,80 @@
+package clinton_street_theater
+
+import (
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/pipeline"
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/scrapers/scrapertest"
+)
+
+func TestScrape_Golden(t *testing.T) {
+	opts := scrapers.ScrapeOptions{
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	scrapertest.Golden(t, NewScraper(), opts, "testdata", "testdata/showtimes.golden.json")
+}
+
+func TestScrape_CalendarAndListViewsDedupe(t *testing.T) {
+	opts := scrapers.ScrapeOptions{
+		From: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	scraped := scrapertest.Replay(t, NewScraper(), opts, "testdata")
+	if len(scraped) != 5 {
+		t.Fatalf("scraped %d showtimes, want 3 from the calendar and 2 from the list", len(scraped))
+	}
+
+	prices := map[string]float64{}
+	for _, st := range pipeline.Dedupe(scraped) {
+		if _, exists := prices[st.MovieTitle]; exists {
+			t.Errorf("%q on %s at %s is still duplicated", st.MovieTitle, st.Date, st.Time)
+		}
+		prices[st.MovieTitle] = st.Price
+	}
+
+	want := map[string]float64{"Wings of Desire": 12, "Stalker": 9, "Repo Man": 0}
+	if len(prices) != len(want) {
+		t.Fatalf("deduped titles = %v, want %v", prices, want)
+	}
+	for title, price := range want {
+		if prices[title] != price {
+			t.Errorf("%s price = %v, want %v", title, prices[title], price)
+		}
+	}
+}
+
+func TestParseDateTime_YearBoundary(t *testing.T) {
+	s := NewScraper()
+	december := time.Date(2026, 12, 20, 18, 0, 0, 0, time.UTC)
+	januaryPage := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := scrapers.ScrapeOptions{Now: december}
+
+	tests := []struct {
+		input    string
+		page     time.Time
+		wantDate string
+		wantTime string
+	}{
+		{"Thursday, January 7 @ 7:00 PM", januaryPage, "2027-01-07", "19:00"},
+		{"Thursday, January 7 @ 7:00 PM", time.Time{}, "2027-01-07", "19:00"},
+		{"Wednesday, December 30 @ 9:30 pm", januaryPage, "2026-12-30", "21:30"},
+		{"Today @ 11:59 PM", time.Time{}, "2026-12-20", "23:59"},
+		{"Sat, Jan 2nd @ 12:00 PM", januaryPage, "2027-01-02", "12:00"},
+		{"January 8 @ 7:00 pm - 9:30 pm", januaryPage, "2027-01-08", "19:00"},
+		{"Friday, January 8", januaryPage, "2027-01-08", ""},
+		{"January 9 @ TBA", januaryPage, "2027-01-09", ""},
+		{"Coming soon", januaryPage, "", ""},
+	}
+
+	for _, tt := range tests {
+		date, showTime := s.parseDateTime(tt.input, opts.Dates(tt.page))
+		if date != tt.wantDate || showTime != tt.wantTime {
+			t.Errorf("parseDateTime(%q) = %q, %q; want %q, %q", tt.input, date, showTime, tt.wantDate, tt.wantTime)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package scrapers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gocolly/colly/v2"
+	"theater-showtimes/internal/events"
+)
+
+// NewCollector creates a colly collector bound to ctx. Pending requests are
+// aborted once ctx is done and in-flight fetches are cancelled with it.
+// Failed fetches are retried according to retry, every request is counted
+// in opts.Stats, and every fetched or failed page is recorded in opts.Outcome
+// and reported through opts.Emit.
+func NewCollector(ctx context.Context, opts ScrapeOptions, retry RetryPolicy, options ...colly.CollectorOption) *colly.Collector {
+	c := colly.NewCollector(options...)
+
+	base := opts.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.WithTransport(&contextTransport{
+		ctx:  ctx,
+		base: &retryTransport{policy: retry, opts: opts, base: base},
+	})
+
+	// Each attempt has its own timeout; retries and their backoff must not
+	// count against colly's overall request timeout
+	c.SetRequestTimeout(0)
+
+	c.OnRequest(func(r *colly.Request) {
+		if ctx.Err() != nil {
+			r.Abort()
+		}
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		opts.Outcome.PageFetched()
+		opts.Emit(events.Event{
+			Type:       events.PageFetched,
+			URL:        r.Request.URL.String(),
+			StatusCode: r.StatusCode,
+		})
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		opts.Outcome.PageFailed(r.Request.URL.String())
+		opts.Emit(events.Event{
+			Type:       events.PageFailed,
+			URL:        r.Request.URL.String(),
+			StatusCode: r.StatusCode,
+			Error:      err.Error(),
+		})
+	})
+
+	return c
+}
+
+// Limit applies a politeness rule to c, with its random delay replaced by
+// RateLimit when that is set. With NoDelay it does nothing.
+func (o ScrapeOptions) Limit(c *colly.Collector, rule *colly.LimitRule) error {
+	if o.NoDelay {
+		return nil
+	}
+	if o.RateLimit > 0 {
+		configured := *rule
+		configured.RandomDelay = o.RateLimit
+		rule = &configured
+	}
+	return c.Limit(rule)
+}
+
+// contextTransport attaches a context to every outgoing request so that
+// cancelling the scrape also cancels the underlying HTTP round trip
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,28 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestScrapeOptions_Limit(t *testing.T) {
+	// A rule without a domain pattern is rejected by colly
+	invalid := &colly.LimitRule{RandomDelay: time.Second}
+
+	if err := (ScrapeOptions{}).Limit(colly.NewCollector(), invalid); err == nil {
+		t.Error("Limit() error = nil, want colly's error for an invalid rule")
+	}
+	if err := (ScrapeOptions{NoDelay: true}).Limit(colly.NewCollector(), invalid); err != nil {
+		t.Errorf("Limit() with NoDelay error = %v, want the rule skipped", err)
+	}
+
+	rule := &colly.LimitRule{DomainGlob: "*", RandomDelay: 2 * time.Second}
+	if err := (ScrapeOptions{RateLimit: 5 * time.Second}).Limit(colly.NewCollector(), rule); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	if rule.RandomDelay != 2*time.Second {
+		t.Errorf("scraper's rule RandomDelay = %s, want it left unchanged by RateLimit", rule.RandomDelay)
+	}
+}
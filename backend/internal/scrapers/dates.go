@@ -0,0 +1,174 @@
+package scrapers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the YYYY-MM-DD format showtime dates are stored in
+const dateLayout = "2006-01-02"
+
+// pastWindowDays is how far before the reference date a date without a
+// year may fall before it is taken to be next year's
+const pastWindowDays = 90
+
+// numericDate matches US-style dates such as 3/4 or 3/4/2026
+var numericDate = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+
+// ordinalDay matches a day of the month with an optional ordinal suffix
+var ordinalDay = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+
+// monthNames maps full month names and their common abbreviations
+var monthNames = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// weekdayNames are the day names and abbreviations ignored in dates
+var weekdayNames = map[string]bool{
+	"monday": true, "mon": true,
+	"tuesday": true, "tue": true, "tues": true,
+	"wednesday": true, "wed": true,
+	"thursday": true, "thu": true, "thur": true, "thurs": true,
+	"friday": true, "fri": true,
+	"saturday": true, "sat": true,
+	"sunday": true, "sun": true,
+}
+
+// DateResolver turns the dates theater websites print, which usually
+// leave out the year, into YYYY-MM-DD dates
+type DateResolver struct {
+	// Now is the moment "Today" and "Tomorrow" refer to. They are resolved
+	// in the theaters' time zone, whatever the server's zone is.
+	Now time.Time
+
+	// Near is a date the listing is known to be close to, such as the
+	// first day of the month page being scraped. A missing year is
+	// inferred from Near, or from Now when Near is zero: a date up to
+	// 90 days earlier stays in that year, anything earlier moves to the
+	// next, so a January listing scraped in December lands in January.
+	Near time.Time
+}
+
+// Dates returns a resolver for a page listing showtimes around near; a zero
+// near means the listing is around the scrape's current time
+func (o ScrapeOptions) Dates(near time.Time) DateResolver {
+	now := o.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return DateResolver{Now: now, Near: near}
+}
+
+// Resolve parses text such as "Today", "Tomorrow", "2026-03-04", "3/4",
+// "Wed, Mar 4th" or "Wednesday, March 4, 2026" into a YYYY-MM-DD date
+func (r DateResolver) Resolve(text string) (string, error) {
+	cleaned := strings.ToLower(strings.Join(strings.Fields(text), " "))
+
+	switch cleaned {
+	case "":
+		return "", fmt.Errorf("empty date")
+	case "today", "tonight":
+		return r.Now.In(Location).Format(dateLayout), nil
+	case "tomorrow":
+		return r.Now.In(Location).AddDate(0, 0, 1).Format(dateLayout), nil
+	}
+
+	if day, err := time.Parse(dateLayout, cleaned); err == nil {
+		return day.Format(dateLayout), nil
+	}
+
+	if match := numericDate.FindStringSubmatch(cleaned); match != nil {
+		month, _ := strconv.Atoi(match[1])
+		day, _ := strconv.Atoi(match[2])
+		year := 0
+		if match[3] != "" {
+			year, _ = strconv.Atoi(match[3])
+			if year < 100 {
+				year += 2000
+			}
+		}
+		return r.build(text, year, time.Month(month), day)
+	}
+
+	var month time.Month
+	var day, year int
+	for _, field := range strings.Fields(strings.NewReplacer(",", " ", ".", " ").Replace(cleaned)) {
+		if m, exists := monthNames[field]; exists && month == 0 {
+			month = m
+			continue
+		}
+		if weekdayNames[field] {
+			continue
+		}
+		if match := ordinalDay.FindStringSubmatch(field); match != nil && day == 0 {
+			day, _ = strconv.Atoi(match[1])
+			continue
+		}
+		if len(field) == 4 && year == 0 {
+			if y, err := strconv.Atoi(field); err == nil {
+				year = y
+				continue
+			}
+		}
+		return "", fmt.Errorf("unrecognized date %q", text)
+	}
+
+	return r.build(text, year, month, day)
+}
+
+// build validates a date, inferring the year when it is zero
+func (r DateResolver) build(text string, year int, month time.Month, day int) (string, error) {
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return "", fmt.Errorf("unrecognized date %q", text)
+	}
+
+	if year == 0 {
+		return r.inferYear(text, month, day)
+	}
+
+	date, ok := validDate(year, month, day)
+	if !ok {
+		return "", fmt.Errorf("invalid date %q", text)
+	}
+	return date.Format(dateLayout), nil
+}
+
+// inferYear picks the first year in which month and day fall no more than
+// pastWindowDays before the reference date. Listings are mostly upcoming, so a
+// date that would be further in the past belongs to the following year.
+func (r DateResolver) inferYear(text string, month time.Month, day int) (string, error) {
+	ref := r.Near
+	if ref.IsZero() {
+		ref = r.Now.In(Location)
+	}
+	ref = time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC)
+	earliest := ref.AddDate(0, 0, -pastWindowDays)
+
+	for year := ref.Year() - 1; year <= ref.Year()+4; year++ {
+		date, ok := validDate(year, month, day)
+		if ok && !date.Before(earliest) {
+			return date.Format(dateLayout), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", text)
+}
+
+// validDate builds a date, reporting false for days the month does not have
+func validDate(year int, month time.Month, day int) (time.Time, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return date, date.Month() == month && date.Day() == day
+}
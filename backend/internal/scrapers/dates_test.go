@@ -0,0 +1,61 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateResolver_Resolve(t *testing.T) {
+	midMarch := time.Date(2026, 3, 15, 20, 0, 0, 0, time.UTC)
+	lateDecember := time.Date(2026, 12, 28, 20, 0, 0, 0, time.UTC)
+	januaryPage := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Already March 16 in UTC, still March 15 at the theaters
+	eveningInPortland := time.Date(2026, 3, 16, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text    string
+		now     time.Time
+		near    time.Time
+		want    string
+		wantErr bool
+	}{
+		{"2026-03-04", midMarch, time.Time{}, "2026-03-04", false},
+		{"Today", midMarch, time.Time{}, "2026-03-15", false},
+		{"  tonight ", midMarch, time.Time{}, "2026-03-15", false},
+		{"Tomorrow", lateDecember, time.Time{}, "2026-12-29", false},
+		{"Today", eveningInPortland, time.Time{}, "2026-03-15", false},
+		{"Tomorrow", eveningInPortland, time.Time{}, "2026-03-16", false},
+		{"Wednesday, March 4", midMarch, time.Time{}, "2026-03-04", false},
+		{"Wed, Mar 4th", midMarch, time.Time{}, "2026-03-04", false},
+		{"Sept. 21st", midMarch, time.Time{}, "2026-09-21", false},
+		{"21 March", midMarch, time.Time{}, "2026-03-21", false},
+		{"3/4", midMarch, time.Time{}, "2026-03-04", false},
+		{"3/4/27", midMarch, time.Time{}, "2027-03-04", false},
+		{"Thursday, January 7, 2027", midMarch, time.Time{}, "2027-01-07", false},
+
+		// Year boundaries
+		{"Thursday, January 7", lateDecember, time.Time{}, "2027-01-07", false},
+		{"Saturday, January 2", lateDecember, januaryPage, "2027-01-02", false},
+		{"December 30", lateDecember, januaryPage, "2026-12-30", false},
+		{"Dec 20", time.Date(2027, 1, 3, 0, 0, 0, 0, time.UTC), time.Time{}, "2026-12-20", false},
+		{"February 29", time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), time.Time{}, "2028-02-29", false},
+
+		{"", midMarch, time.Time{}, "", true},
+		{"February 30", midMarch, time.Time{}, "", true},
+		{"February 30, 2026", midMarch, time.Time{}, "", true},
+		{"13/4", midMarch, time.Time{}, "", true},
+		{"Smarch 4", midMarch, time.Time{}, "", true},
+		{"sold out", midMarch, time.Time{}, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := DateResolver{Now: tt.now, Near: tt.near}.Resolve(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
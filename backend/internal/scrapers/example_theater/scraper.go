@@ -1,16 +1,21 @@
 package example_theater
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
 )
 
 // Scraper implements the scraper for Example Theater
 type Scraper struct {
 	theater models.Theater
+
+	// Retry controls how failed page fetches are retried
+	Retry scrapers.RetryPolicy
 }
 
 // NewScraper creates a new Example Theater scraper
@@ -24,6 +29,7 @@ func NewScraper() *Scraper {
 			Zip:     "12345",
 			Website: "https://example-theater.com",
 		},
+		Retry: scrapers.DefaultRetryPolicy,
 	}
 }
 
@@ -38,43 +44,58 @@ func (s *Scraper) GetID() string {
 }
 
 // Scrape performs the actual scraping
-func (s *Scraper) Scrape() ([]models.Showtime, error) {
+func (s *Scraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
 	showtimes := []models.Showtime{}
 
-	c := colly.NewCollector(
+	c := scrapers.NewCollector(ctx, opts, s.Retry,
 		colly.AllowedDomains("example-theater.com"),
 	)
 
 	// Rate limiting
-	c.Limit(&colly.LimitRule{
+	if err := opts.Limit(c, &colly.LimitRule{
 		DomainGlob:  "*",
 		RandomDelay: 2 * time.Second,
-	})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set rate limit: %w", err)
+	}
 
 	// Set up callbacks
 	c.OnHTML(".showtime", func(e *colly.HTMLElement) {
 		// Example parsing logic - customize based on actual website structure
 		showtime := models.Showtime{
-			ID:         fmt.Sprintf("%s-%s", s.theater.ID, e.Attr("data-id")),
 			TheaterID:  s.theater.ID,
 			MovieTitle: e.ChildText(".movie-title"),
-			Date:       e.ChildText(".date"),
 			Time:       e.ChildText(".time"),
 			Format:     e.ChildText(".format"),
 			Link: e.ChildAttr(".booking-link", "href"),
 		}
+		if showtime.MovieTitle == "" {
+			opts.Skipf(".showtime element without a title on %s", e.Request.URL)
+			return
+		}
+		date, err := opts.Dates(time.Time{}).Resolve(e.ChildText(".date"))
+		if err != nil {
+			opts.Skipf("%q has an unparsable date: %v", showtime.MovieTitle, err)
+			return
+		}
+		showtime.Date = date
+		showtime.ID = scrapers.ShowtimeID(showtime)
 		showtimes = append(showtimes, showtime)
+		opts.EmitShowtime(showtime)
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
-		fmt.Printf("Error scraping %s: %v\n", r.Request.URL, err)
+		opts.Logf("Error scraping %s: %v", r.Request.URL, err)
 	})
 
 	// Start scraping
 	err := c.Visit(s.theater.Website + "/showtimes")
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("failed to visit website: %w", err)
 	}
 
-	return showtimes, nil
+	return opts.FilterWindow(showtimes), nil
 }
@@ -0,0 +1,19 @@
+package example_theater
+
+import (
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/scrapers/scrapertest"
+)
+
+func TestScrape_Golden(t *testing.T) {
+	opts := scrapers.ScrapeOptions{
+		Now:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	scrapertest.Golden(t, NewScraper(), opts, "testdata", "testdata/showtimes.golden.json")
+}
@@ -0,0 +1,135 @@
+package scrapers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// unsafeFixtureChars matches characters kept out of fixture file names
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FixturePath maps a page URL to its fixture file, relative to the fixture
+// directory: the host without "www.", then the URL path, with "index" for
+// paths ending in a slash and the query string folded into the file name
+func FixturePath(page *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(page.Hostname()), "www.")
+
+	name := page.Path
+	if name == "" || strings.HasSuffix(name, "/") {
+		name += "index"
+	}
+	if page.RawQuery != "" {
+		name += "__" + unsafeFixtureChars.ReplaceAllString(page.RawQuery, "_")
+	}
+
+	return filepath.Join(host, filepath.FromSlash(path.Clean("/"+name))) + ".html"
+}
+
+// RecordingTransport saves every page fetched with a 200 response under Dir,
+// at its FixturePath, so the scrape can later be replayed offline with a
+// FixtureServer
+type RecordingTransport struct {
+	Dir string
+
+	// Base performs the requests; http.DefaultTransport is used when nil
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", req.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	file := filepath.Join(t.Dir, FixturePath(req.URL))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(file, body, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+// FixtureServer serves pages recorded by RecordingTransport from a local
+// HTTP server. Pages that were never recorded get a 404.
+type FixtureServer struct {
+	*httptest.Server
+	dir string
+}
+
+// NewFixtureServer starts a server for the fixtures under dir; Close it when done
+func NewFixtureServer(dir string) *FixtureServer {
+	s := &FixtureServer{dir: dir}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Transport returns a transport that sends every request to the fixture
+// server, whatever its original host, for use as ScrapeOptions.Transport
+func (s *FixtureServer) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.URL)
+	return &replayTransport{target: target, base: s.Client().Transport}
+}
+
+// serve writes the fixture recorded for the requested page. The original
+// host arrives in the Host header.
+func (s *FixtureServer) serve(w http.ResponseWriter, r *http.Request) {
+	page := *r.URL
+	page.Host = r.Host
+
+	data, err := os.ReadFile(filepath.Join(s.dir, FixturePath(&page)))
+	if err != nil {
+		http.Error(w, "no fixture recorded for "+r.Host+r.URL.RequestURI(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+// replayTransport redirects requests to a fixture server
+type replayTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Host = req.URL.Host
+	out.URL.Scheme = t.target.Scheme
+	out.URL.Host = t.target.Host
+
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report the original URL so colly resolves links against the real site
+	resp.Request = req
+	return resp, nil
+}
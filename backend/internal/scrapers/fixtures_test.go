@@ -0,0 +1,82 @@
+package scrapers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestFixturePath(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.cstpdx.com/schedule/month/2026-03/", "cstpdx.com/schedule/month/2026-03/index.html"},
+		{"https://example-theater.com/showtimes", "example-theater.com/showtimes.html"},
+		{"https://example-theater.com", "example-theater.com/index.html"},
+		{"https://example-theater.com/list/?page=2&sort=date", "example-theater.com/list/index__page_2_sort_date.html"},
+		{"https://example-theater.com/../../etc/passwd", "example-theater.com/etc/passwd.html"},
+		{"http://127.0.0.1:8080/now-showing", "127.0.0.1/now-showing.html"},
+	}
+
+	for _, tt := range tests {
+		page, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) error = %v", tt.url, err)
+		}
+		if got := FixturePath(page); got != filepath.FromSlash(tt.want) {
+			t.Errorf("FixturePath(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/showtimes" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><body><a class="movie" href="/films/alien">Alien</a></body></html>`))
+	}))
+
+	// scrape collects the movie link from the showtimes page
+	scrape := func(transport http.RoundTripper, page string) (string, error) {
+		var link string
+		c := NewCollector(context.Background(), ScrapeOptions{Transport: transport}, noRetry)
+		c.OnHTML("a.movie", func(e *colly.HTMLElement) {
+			link = e.Request.AbsoluteURL(e.Attr("href"))
+		})
+		err := c.Visit(origin.URL + page)
+		return link, err
+	}
+
+	recorded, err := scrape(&RecordingTransport{Dir: dir}, "/showtimes")
+	if err != nil {
+		t.Fatalf("recording scrape error = %v", err)
+	}
+	if _, err := scrape(&RecordingTransport{Dir: dir}, "/missing"); err == nil {
+		t.Fatal("recording a missing page succeeded")
+	}
+	origin.Close()
+
+	server := NewFixtureServer(dir)
+	defer server.Close()
+
+	replayed, err := scrape(server.Transport(), "/showtimes")
+	if err != nil {
+		t.Fatalf("replayed scrape error = %v", err)
+	}
+	if replayed != recorded || replayed != origin.URL+"/films/alien" {
+		t.Errorf("replayed link = %q, want %q resolved against the original site", replayed, recorded)
+	}
+
+	if _, err := scrape(server.Transport(), "/missing"); err == nil {
+		t.Error("replaying an unrecorded page succeeded")
+	}
+}
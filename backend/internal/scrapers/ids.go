@@ -0,0 +1,70 @@
+package scrapers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"theater-showtimes/internal/models"
+)
+
+// idHashLength is how many hex digits of the hash a showtime ID keeps
+const idHashLength = 16
+
+// ShowtimeID derives a stable ID for a showtime from its theater, start
+// date and time, screen and the URL of its event page, so the same screening gets
+// the same ID on every scrape however its title is cleaned up. When a
+// listing has neither a screen nor a link, its normalized title stands in
+// for them, so different films starting together at a theater stay apart.
+func ShowtimeID(st models.Showtime) string {
+	// Hash the canonical HH:MM so "7:00 PM" and "19:00" give the same ID.
+	// A time that does not parse is unknown, as Normalize records it.
+	clock, err := ParseClock(st.Time)
+	if err != nil {
+		clock = ""
+	}
+
+	parts := []string{
+		st.TheaterID,
+		strings.TrimSpace(st.Date) + "T" + clock,
+		strings.ToLower(strings.Join(strings.Fields(st.Screen), " ")),
+		canonicalURL(st.Link),
+	}
+	if parts[2] == "" && parts[3] == "" {
+		parts = append(parts, idTitle(st.MovieTitle))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return st.TheaterID + "-" + hex.EncodeToString(sum[:])[:idHashLength]
+}
+
+// canonicalURL reduces an event link to the parts that identify the page:
+// the host without "www.", the path without a trailing slash and the query,
+// so http and https or a stray fragment do not change the ID
+func canonicalURL(link string) string {
+	link = strings.TrimSpace(link)
+	page, err := url.Parse(link)
+	if err != nil || page.Host == "" {
+		return link
+	}
+
+	canonical := strings.TrimPrefix(strings.ToLower(page.Hostname()), "www.") +
+		strings.TrimSuffix(page.EscapedPath(), "/")
+	if page.RawQuery != "" {
+		canonical += "?" + page.RawQuery
+	}
+	return canonical
+}
+
+// idTitle keeps only the letters and digits of a title, lowercased, so
+// spacing and punctuation tweaks do not change an ID built from it
+func idTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, title)
+}
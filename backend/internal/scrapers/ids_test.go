@@ -0,0 +1,76 @@
+package scrapers
+
+import (
+	"strings"
+	"testing"
+
+	"theater-showtimes/internal/models"
+)
+
+func TestShowtimeID(t *testing.T) {
+	base := models.Showtime{
+		TheaterID:  "clinton-street-theater",
+		MovieTitle: "Repo Man",
+		Date:       "2026-03-04",
+		Time:       "19:00",
+		Link:       "https://cstpdx.com/event/repo-man/",
+	}
+	id := ShowtimeID(base)
+
+	if !strings.HasPrefix(id, "clinton-street-theater-") || len(id) != len("clinton-street-theater-")+idHashLength {
+		t.Fatalf("ShowtimeID() = %q, want the theater ID and a %d digit hash", id, idHashLength)
+	}
+
+	same := []struct {
+		name   string
+		change func(*models.Showtime)
+	}{
+		{"title cleanup", func(st *models.Showtime) { st.MovieTitle = "Repo Man (1984)" }},
+		{"price and format", func(st *models.Showtime) { st.Price = 10; st.Format = "35mm" }},
+		{"link scheme and www", func(st *models.Showtime) { st.Link = "http://www.cstpdx.com/event/repo-man" }},
+		{"link fragment", func(st *models.Showtime) { st.Link = "https://cstpdx.com/event/repo-man/#tickets" }},
+		{"time format", func(st *models.Showtime) { st.Time = "7:00 PM" }},
+	}
+	for _, tt := range same {
+		st := base
+		tt.change(&st)
+		if got := ShowtimeID(st); got != id {
+			t.Errorf("%s: ShowtimeID() = %q, want unchanged %q", tt.name, got, id)
+		}
+	}
+
+	different := []struct {
+		name   string
+		change func(*models.Showtime)
+	}{
+		{"theater", func(st *models.Showtime) { st.TheaterID = "local-cinema" }},
+		{"date", func(st *models.Showtime) { st.Date = "2026-03-05" }},
+		{"time", func(st *models.Showtime) { st.Time = "21:30" }},
+		{"unknown time", func(st *models.Showtime) { st.Time = "TBA" }},
+		{"screen", func(st *models.Showtime) { st.Screen = "Screen 2" }},
+		{"link", func(st *models.Showtime) { st.Link = "https://cstpdx.com/event/repo-man-2/" }},
+		{"link query", func(st *models.Showtime) { st.Link = "https://cstpdx.com/event/repo-man/?occurrence=2" }},
+	}
+	for _, tt := range different {
+		st := base
+		tt.change(&st)
+		if got := ShowtimeID(st); got == id {
+			t.Errorf("%s: ShowtimeID() = %q, want a different ID", tt.name, got)
+		}
+	}
+}
+
+func TestShowtimeID_WithoutScreenOrLink(t *testing.T) {
+	alien := models.Showtime{TheaterID: "local-cinema", MovieTitle: "Alien", Date: "2026-03-04", Time: "19:00"}
+	heat := alien
+	heat.MovieTitle = "Heat"
+	alienRespaced := alien
+	alienRespaced.MovieTitle = " ALIEN! "
+
+	if ShowtimeID(alien) == ShowtimeID(heat) {
+		t.Error("films starting together without a screen or link share an ID")
+	}
+	if ShowtimeID(alien) != ShowtimeID(alienRespaced) {
+		t.Error("spacing and punctuation in the title changed the ID")
+	}
+}
@@ -1,16 +1,21 @@
 package local_cinema
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
 )
 
 // Scraper implements the scraper for Local Cinema
 type Scraper struct {
 	theater models.Theater
+
+	// Retry controls how failed page fetches are retried
+	Retry scrapers.RetryPolicy
 }
 
 // NewScraper creates a new Local Cinema scraper
@@ -24,6 +29,7 @@ func NewScraper() *Scraper {
 			Zip:     "12345",
 			Website: "https://local-cinema.com",
 		},
+		Retry: scrapers.DefaultRetryPolicy,
 	}
 }
 
@@ -38,41 +44,56 @@ func (s *Scraper) GetID() string {
 }
 
 // Scrape performs the actual scraping
-func (s *Scraper) Scrape() ([]models.Showtime, error) {
+func (s *Scraper) Scrape(ctx context.Context, opts scrapers.ScrapeOptions) ([]models.Showtime, error) {
 	showtimes := []models.Showtime{}
 
-	c := colly.NewCollector(
+	c := scrapers.NewCollector(ctx, opts, s.Retry,
 		colly.AllowedDomains("local-cinema.com"),
 	)
 
 	// Rate limiting
-	c.Limit(&colly.LimitRule{
+	if err := opts.Limit(c, &colly.LimitRule{
 		DomainGlob:  "*",
 		RandomDelay: 2 * time.Second,
-	})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set rate limit: %w", err)
+	}
 
 	// Set up callbacks - customize based on actual website structure
 	c.OnHTML(".movie-listing", func(e *colly.HTMLElement) {
 		// Example parsing logic
 		showtime := models.Showtime{
-			ID:         fmt.Sprintf("%s-%d", s.theater.ID, time.Now().Unix()),
 			TheaterID:  s.theater.ID,
 			MovieTitle: e.ChildText("h3.title"),
-			Date:       e.ChildText(".show-date"),
 			Time:       e.ChildText(".show-time"),
 			Format:     e.ChildText(".format"),
 		}
+		if showtime.MovieTitle == "" {
+			opts.Skipf(".movie-listing element without a title on %s", e.Request.URL)
+			return
+		}
+		date, err := opts.Dates(time.Time{}).Resolve(e.ChildText(".show-date"))
+		if err != nil {
+			opts.Skipf("%q has an unparsable date: %v", showtime.MovieTitle, err)
+			return
+		}
+		showtime.Date = date
+		showtime.ID = scrapers.ShowtimeID(showtime)
 		showtimes = append(showtimes, showtime)
+		opts.EmitShowtime(showtime)
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
-		fmt.Printf("Error scraping %s: %v\n", r.Request.URL, err)
+		opts.Logf("Error scraping %s: %v", r.Request.URL, err)
 	})
 
 	err := c.Visit(s.theater.Website + "/now-showing")
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("failed to visit website: %w", err)
 	}
 
-	return showtimes, nil
+	return opts.FilterWindow(showtimes), nil
 }
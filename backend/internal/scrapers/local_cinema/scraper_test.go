@@ -0,0 +1,41 @@
+package local_cinema
+
+import (
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/scrapers"
+	"theater-showtimes/internal/scrapers/scrapertest"
+)
+
+func TestScrape_Golden(t *testing.T) {
+	opts := scrapers.ScrapeOptions{
+		Now:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	scrapertest.Golden(t, NewScraper(), opts, "testdata", "testdata/showtimes.golden.json")
+}
+
+func TestScrape_IDsAreStableAcrossRuns(t *testing.T) {
+	opts := scrapers.ScrapeOptions{
+		Now:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	first := scrapertest.Replay(t, NewScraper(), opts, "testdata")
+	second := scrapertest.Replay(t, NewScraper(), opts, "testdata")
+
+	seen := make(map[string]bool)
+	for i, st := range first {
+		if seen[st.ID] {
+			t.Errorf("ID %s is shared by more than one showtime", st.ID)
+		}
+		seen[st.ID] = true
+		if second[i].ID != st.ID {
+			t.Errorf("%q on %s at %s got ID %s, then %s", st.MovieTitle, st.Date, st.Time, st.ID, second[i].ID)
+		}
+	}
+}
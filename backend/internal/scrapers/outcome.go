@@ -0,0 +1,129 @@
+package scrapers
+
+import (
+	"fmt"
+	"sync"
+)
+
+// maxWarnings caps the warnings kept per scrape so a badly broken page
+// cannot bloat the stored metadata
+const maxWarnings = 20
+
+// Outcome records how the pages of one scrape went: how many were fetched,
+// which failed, what the scraper warned about and how many listings it found
+// but could not parse. It is safe for concurrent use, and its methods accept
+// a nil receiver.
+type Outcome struct {
+	mu         sync.Mutex
+	fetched    int
+	failedURLs []string
+	warnings   []string
+	dropped    int
+	skipped    int
+}
+
+// PageFetched records a page that was downloaded successfully
+func (o *Outcome) PageFetched() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fetched++
+}
+
+// PageFailed records a page that could not be fetched. A URL reported more
+// than once is only listed once.
+func (o *Outcome) PageFailed(url string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, failed := range o.failedURLs {
+		if failed == url {
+			return
+		}
+	}
+	o.failedURLs = append(o.failedURLs, url)
+}
+
+// Warn records a problem that did not stop the scrape
+func (o *Outcome) Warn(message string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.warnings) >= maxWarnings {
+		o.dropped++
+		return
+	}
+	o.warnings = append(o.warnings, message)
+}
+
+// Skip records a listing that was found on a page but could not be parsed
+func (o *Outcome) Skip() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.skipped++
+}
+
+// PagesFetched returns the number of pages downloaded successfully
+func (o *Outcome) PagesFetched() int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.fetched
+}
+
+// FailedURLs returns the pages that could not be fetched, in failure order
+func (o *Outcome) FailedURLs() []string {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.failedURLs...)
+}
+
+// Warnings returns the recorded warnings, noting how many were dropped
+// beyond the cap
+func (o *Outcome) Warnings() []string {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	warnings := append([]string(nil), o.warnings...)
+	if o.dropped > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d more warnings not recorded", o.dropped))
+	}
+	return warnings
+}
+
+// Skipped returns the number of listings that could not be parsed
+func (o *Outcome) Skipped() int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.skipped
+}
+
+// AllFailed reports whether the scrape tried at least one page and every
+// page it tried failed
+func (o *Outcome) AllFailed() bool {
+	if o == nil {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.fetched == 0 && len(o.failedURLs) > 0
+}
@@ -0,0 +1,65 @@
+package scrapers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCollector_RecordsOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	outcome := &Outcome{}
+	c := NewCollector(context.Background(), ScrapeOptions{Outcome: outcome}, noRetry)
+	c.AllowURLRevisit = true
+
+	c.Visit(server.URL + "/ok")
+	c.Visit(server.URL + "/missing")
+	c.Visit(server.URL + "/missing")
+
+	if got := outcome.PagesFetched(); got != 1 {
+		t.Errorf("PagesFetched() = %d, want 1", got)
+	}
+	if got := outcome.FailedURLs(); len(got) != 1 || got[0] != server.URL+"/missing" {
+		t.Errorf("FailedURLs() = %v, want the missing page once", got)
+	}
+	if outcome.AllFailed() {
+		t.Error("AllFailed() = true with a fetched page")
+	}
+}
+
+func TestOutcome_CapsWarnings(t *testing.T) {
+	outcome := &Outcome{}
+	for i := 0; i < maxWarnings+3; i++ {
+		outcome.Warn(fmt.Sprintf("warning %d", i))
+	}
+
+	warnings := outcome.Warnings()
+	if len(warnings) != maxWarnings+1 {
+		t.Fatalf("got %d warnings, want %d", len(warnings), maxWarnings+1)
+	}
+	if last := warnings[maxWarnings]; last != "3 more warnings not recorded" {
+		t.Errorf("last warning = %q, want the dropped count", last)
+	}
+}
+
+func TestOutcome_NilIsSafe(t *testing.T) {
+	var outcome *Outcome
+	outcome.PageFetched()
+	outcome.PageFailed("https://example.com")
+	outcome.Warn("ignored")
+	outcome.Skip()
+
+	if outcome.PagesFetched() != 0 || outcome.FailedURLs() != nil || outcome.Warnings() != nil || outcome.Skipped() != 0 || outcome.AllFailed() {
+		t.Error("nil Outcome recorded something")
+	}
+}
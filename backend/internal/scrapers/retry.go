@@ -0,0 +1,231 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"theater-showtimes/internal/events"
+)
+
+// RetryPolicy controls how failed page fetches are retried. Fetches are
+// retried on 5xx and 429 responses, timeouts and dropped connections.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries per page, including the first.
+	// Values below 1 mean a single try.
+	MaxAttempts int
+
+	// BaseDelay is the wait before the first retry; it doubles on each retry
+	BaseDelay time.Duration
+
+	// MaxDelay caps any single wait, including one requested by Retry-After
+	MaxDelay time.Duration
+
+	// AttemptTimeout bounds each try, including reading the response body.
+	// Zero uses DefaultRetryPolicy's, so a fetch never waits forever.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy suits small theater websites: three tries over a few seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      time.Second,
+	MaxDelay:       30 * time.Second,
+	AttemptTimeout: 20 * time.Second,
+}
+
+// attemptTimeout returns the bound on each try
+func (p RetryPolicy) attemptTimeout() time.Duration {
+	if p.AttemptTimeout <= 0 {
+		return DefaultRetryPolicy.AttemptTimeout
+	}
+	return p.AttemptTimeout
+}
+
+// backoff returns the wait before retry number retry (1-based)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// FetchStats counts the HTTP requests made during a scrape. It is safe for
+// concurrent use, and its methods accept a nil receiver.
+type FetchStats struct {
+	attempts atomic.Int64
+	retries  atomic.Int64
+}
+
+// Attempts returns the number of requests sent, including retries
+func (s *FetchStats) Attempts() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.attempts.Load())
+}
+
+// Retries returns the number of requests that repeated a failed one
+func (s *FetchStats) Retries() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.retries.Load())
+}
+
+func (s *FetchStats) record(retry bool) {
+	if s == nil {
+		return
+	}
+	s.attempts.Add(1)
+	if retry {
+		s.retries.Add(1)
+	}
+}
+
+// retryTransport retries failed round trips according to policy. Requests
+// with a body that cannot be replayed are tried once.
+type retryTransport struct {
+	policy RetryPolicy
+	opts   ScrapeOptions
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxAttempts := t.policy.MaxAttempts
+	if req.Body != nil && req.GetBody == nil {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		t.opts.Stats.record(attempt > 1)
+		resp, err := t.try(req)
+
+		if attempt >= maxAttempts || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = after
+				if t.policy.MaxDelay > 0 && delay > t.policy.MaxDelay {
+					delay = t.policy.MaxDelay
+				}
+			}
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t.opts.Logf("Retrying %s in %s after attempt %d: %s", req.URL, delay, attempt, reason)
+		t.opts.Emit(events.Event{Type: events.PageRetry, URL: req.URL.String(), Error: reason})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// try performs one round trip, bounded by the policy's attempt timeout
+func (t *retryTransport) try(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.policy.attemptTimeout())
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout also covers reading the body, so release it only on Close
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a per-attempt context once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// retryable reports whether a failed round trip is worth repeating. Nothing
+// is retried once the scrape itself is cancelled.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return true // the attempt timed out
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return true
+		case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+			return true
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return true
+		}
+		return false
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses a Retry-After header given as seconds or an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package scrapers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// fastRetry keeps test backoff short
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, AttemptTimeout: time.Second}
+
+// noRetry tries each page once
+var noRetry = RetryPolicy{MaxAttempts: 1}
+
+// flakyServer fails the first failures requests with handler, then serves "ok"
+func flakyServer(t *testing.T, failures int32, fail http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			fail(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func TestNewCollector_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		fail         http.HandlerFunc
+		wantErr      bool
+		wantRequests int32
+		wantRetries  int
+	}{
+		{"recovers from 5xx", 2, status(http.StatusServiceUnavailable), false, 3, 2},
+		{"recovers from 429", 1, status(http.StatusTooManyRequests), false, 2, 1},
+		{"gives up after max attempts", 5, status(http.StatusBadGateway), true, 3, 2},
+		{"does not retry 404", 5, status(http.StatusNotFound), true, 1, 0},
+		{"retries a timed out attempt", 1, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}, false, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.failures, tt.fail)
+			stats := &FetchStats{}
+			policy := fastRetry
+			policy.AttemptTimeout = 100 * time.Millisecond
+
+			c := NewCollector(context.Background(), ScrapeOptions{Stats: stats}, policy)
+			var body string
+			c.OnResponse(func(r *colly.Response) { body = string(r.Body) })
+
+			err := c.Visit(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Visit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && body != "ok" {
+				t.Errorf("body = %q, want ok", body)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantRequests)
+			}
+			if stats.Attempts() != int(tt.wantRequests) || stats.Retries() != tt.wantRetries {
+				t.Errorf("stats = %d attempts/%d retries, want %d/%d", stats.Attempts(), stats.Retries(), tt.wantRequests, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestNewCollector_RetryStopsWhenCancelled(t *testing.T) {
+	server, requests := flakyServer(t, 5, status(http.StatusServiceUnavailable))
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	if err := NewCollector(ctx, ScrapeOptions{}, policy).Visit(server.URL); err == nil {
+		t.Fatal("Visit() succeeded, want cancellation error")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Visit() took %s after cancellation", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Sun, 01 Mar 2026 12:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 01 Mar 2026 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := policy.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", retry, got, want)
+		}
+	}
+}
+
+func TestRetryPolicy_AttemptTimeout(t *testing.T) {
+	if got := (RetryPolicy{}).attemptTimeout(); got != DefaultRetryPolicy.AttemptTimeout {
+		t.Errorf("zero policy attemptTimeout() = %s, want the default %s", got, DefaultRetryPolicy.AttemptTimeout)
+	}
+	if got := fastRetry.attemptTimeout(); got != time.Second {
+		t.Errorf("attemptTimeout() = %s, want the policy's 1s", got)
+	}
+}
@@ -1,6 +1,13 @@
 package scrapers
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"theater-showtimes/internal/events"
 	"theater-showtimes/internal/models"
 )
 
@@ -8,14 +15,178 @@ import (
 type Scraper interface {
 	// GetTheaterInfo returns basic theater information
 	GetTheaterInfo() models.Theater
-	
-	// Scrape performs the scraping and returns showtimes
-	Scrape() ([]models.Showtime, error)
-	
+
+	// Scrape performs the scraping and returns showtimes. Implementations
+	// must stop fetching and return ctx.Err() once ctx is done.
+	Scrape(ctx context.Context, opts ScrapeOptions) ([]models.Showtime, error)
+
 	// GetID returns the unique identifier for this scraper
 	GetID() string
 }
 
+// LegacyScraper is the original context-free scraper interface. Scrapers
+// that still implement it can be registered with RegisterLegacy.
+type LegacyScraper interface {
+	GetTheaterInfo() models.Theater
+	Scrape() ([]models.Showtime, error)
+	GetID() string
+}
+
+// ScrapeOptions controls a single scrape run
+type ScrapeOptions struct {
+	// From and To bound the showtime dates to collect (inclusive, by day).
+	// A zero value leaves that side of the window open.
+	From time.Time
+	To   time.Time
+
+	// Now is the moment relative dates such as "Today" are resolved
+	// against; time.Now() is used when zero
+	Now time.Time
+
+	// Logger receives progress output; log.Default() is used when nil
+	Logger *log.Logger
+
+	// Events receives structured progress events; nil discards them
+	Events func(events.Event)
+
+	// Stats, if set, counts the HTTP requests made by the scrape
+	Stats *FetchStats
+
+	// Outcome, if set, records failed pages, warnings and unparsable listings
+	Outcome *Outcome
+
+	// Transport, if set, fetches pages instead of http.DefaultTransport; it
+	// is how scrapes are recorded to and replayed from fixtures
+	Transport http.RoundTripper
+
+	// NoDelay skips politeness delays between requests, for scrapes that
+	// replay local fixtures rather than load a theater's website
+	NoDelay bool
+
+	// RateLimit, if set, replaces the longest random delay a scraper waits
+	// between requests to its site
+	RateLimit time.Duration
+}
+
+// Emit sends a progress event to the configured Events sink, if any
+func (o ScrapeOptions) Emit(event events.Event) {
+	if o.Events == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	o.Events(event)
+}
+
+// EmitShowtime reports a showtime extracted from a page
+func (o ScrapeOptions) EmitShowtime(showtime models.Showtime) {
+	o.Emit(events.Event{
+		Type:       events.ShowtimeExtracted,
+		MovieTitle: showtime.MovieTitle,
+		Showtime:   &showtime,
+	})
+}
+
+// Logf writes a progress message to the configured logger
+func (o ScrapeOptions) Logf(format string, args ...interface{}) {
+	logger := o.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf(format, args...)
+}
+
+// Warnf logs a problem that did not stop the scrape and records it in the outcome
+func (o ScrapeOptions) Warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	o.Logf("Warning: %s", message)
+	o.Outcome.Warn(message)
+}
+
+// Skipf logs a listing that could not be parsed and counts it in the outcome
+func (o ScrapeOptions) Skipf(format string, args ...interface{}) {
+	o.Logf("Skipping listing: "+format, args...)
+	o.Outcome.Skip()
+}
+
+// InWindow reports whether a YYYY-MM-DD date falls inside the From/To window.
+// Dates that cannot be parsed are kept so scrapers never drop data silently.
+func (o ScrapeOptions) InWindow(date string) bool {
+	if o.From.IsZero() && o.To.IsZero() {
+		return true
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return true
+	}
+
+	if !o.From.IsZero() && day.Before(truncateDay(o.From)) {
+		return false
+	}
+	if !o.To.IsZero() && day.After(truncateDay(o.To)) {
+		return false
+	}
+	return true
+}
+
+// FilterWindow drops showtimes whose date falls outside the From/To window
+func (o ScrapeOptions) FilterWindow(showtimes []models.Showtime) []models.Showtime {
+	if o.From.IsZero() && o.To.IsZero() {
+		return showtimes
+	}
+
+	filtered := make([]models.Showtime, 0, len(showtimes))
+	for _, st := range showtimes {
+		if o.InWindow(st.Date) {
+			filtered = append(filtered, st)
+		}
+	}
+	return filtered
+}
+
+// truncateDay strips the clock from t, keeping its calendar date
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// legacyAdapter lets a LegacyScraper satisfy the Scraper interface
+type legacyAdapter struct {
+	LegacyScraper
+}
+
+// Adapt wraps a LegacyScraper so it can be used wherever a Scraper is expected.
+// The legacy Scrape call cannot be interrupted, so on cancellation the adapter
+// returns ctx.Err() immediately and discards the result when it eventually arrives.
+func Adapt(scraper LegacyScraper) Scraper {
+	return &legacyAdapter{LegacyScraper: scraper}
+}
+
+// Scrape runs the legacy scraper and honours ctx and the date window
+func (a *legacyAdapter) Scrape(ctx context.Context, opts ScrapeOptions) ([]models.Showtime, error) {
+	type result struct {
+		showtimes []models.Showtime
+		err       error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		showtimes, err := a.LegacyScraper.Scrape()
+		done <- result{showtimes, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return opts.FilterWindow(res.showtimes), nil
+	}
+}
+
 // Registry holds all available scrapers
 type Registry struct {
 	scrapers map[string]Scraper
@@ -33,6 +204,11 @@ func (r *Registry) Register(scraper Scraper) {
 	r.scrapers[scraper.GetID()] = scraper
 }
 
+// RegisterLegacy adds a context-free scraper to the registry via Adapt
+func (r *Registry) RegisterLegacy(scraper LegacyScraper) {
+	r.Register(Adapt(scraper))
+}
+
 // Get retrieves a scraper by ID
 func (r *Registry) Get(id string) (Scraper, bool) {
 	scraper, exists := r.scrapers[id]
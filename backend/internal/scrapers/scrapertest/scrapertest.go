@@ -0,0 +1,169 @@
+// Package scrapertest runs scrapers against recorded pages and compares
+// their output with golden files. Run a scraper's tests with -update to
+// rewrite its golden files after an intended change.
+package scrapertest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/models"
+	"theater-showtimes/internal/scrapers"
+)
+
+// update rewrites golden files with the current scraper output
+var update = flag.Bool("update", false, "rewrite scraper golden files with the current output")
+
+// replayTimeout bounds a scrape of local fixtures
+const replayTimeout = 30 * time.Second
+
+// Replay runs scraper against the pages recorded under fixtures and returns
+// its showtimes. The test fails if the scrape returns an error.
+func Replay(t *testing.T, scraper scrapers.Scraper, opts scrapers.ScrapeOptions, fixtures string) []models.Showtime {
+	t.Helper()
+
+	server := scrapers.NewFixtureServer(fixtures)
+	t.Cleanup(server.Close)
+	opts.Transport = server.Transport()
+	opts.NoDelay = true
+	if opts.Logger == nil {
+		opts.Logger = log.New(testWriter{t}, "", 0)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+
+	showtimes, err := scraper.Scrape(ctx, opts)
+	if err != nil {
+		t.Fatalf("%s Scrape() error = %v", scraper.GetID(), err)
+	}
+	return showtimes
+}
+
+// Golden replays scraper against fixtures and compares its showtimes with
+// the JSON in golden, reporting every added, removed or changed showtime.
+// With -update it rewrites golden instead.
+func Golden(t *testing.T, scraper scrapers.Scraper, opts scrapers.ScrapeOptions, fixtures, golden string) {
+	t.Helper()
+
+	got := Replay(t, scraper, opts, fixtures)
+	if got == nil {
+		got = []models.Showtime{}
+	}
+
+	if *update {
+		data, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to encode showtimes: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(golden, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		t.Logf("updated %s with %d showtimes", golden, len(got))
+		return
+	}
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	var want []models.Showtime
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("failed to decode golden file %s: %v", golden, err)
+	}
+
+	if diff := Diff(want, got); len(diff) > 0 {
+		t.Errorf("%s output differs from %s (run with -update to accept):\n%s",
+			scraper.GetID(), golden, strings.Join(diff, "\n"))
+	}
+}
+
+// Diff describes how got differs from want, one line per removed, added
+// or changed showtime. Showtimes are matched by ID; a repeated ID is
+// matched by its position among the showtimes sharing it.
+func Diff(want, got []models.Showtime) []string {
+	wantByKey, wantKeys := keyed(want)
+	gotByKey, gotKeys := keyed(got)
+
+	var diff []string
+	for _, key := range wantKeys {
+		before := wantByKey[key]
+		after, exists := gotByKey[key]
+		if !exists {
+			diff = append(diff, fmt.Sprintf("- removed %s: %s", key, summary(before)))
+			continue
+		}
+		if changes := fieldChanges(before, after); len(changes) > 0 {
+			diff = append(diff, fmt.Sprintf("~ changed %s: %s", key, strings.Join(changes, ", ")))
+		}
+	}
+	for _, key := range gotKeys {
+		if _, exists := wantByKey[key]; !exists {
+			diff = append(diff, fmt.Sprintf("+ added %s: %s", key, summary(gotByKey[key])))
+		}
+	}
+	return diff
+}
+
+// keyed indexes showtimes by ID, suffixing repeats with #2, #3 and so on,
+// and returns the keys in their original order
+func keyed(showtimes []models.Showtime) (map[string]models.Showtime, []string) {
+	byKey := make(map[string]models.Showtime, len(showtimes))
+	keys := make([]string, 0, len(showtimes))
+	seen := make(map[string]int)
+
+	for _, st := range showtimes {
+		seen[st.ID]++
+		key := st.ID
+		if n := seen[st.ID]; n > 1 {
+			key = fmt.Sprintf("%s#%d", st.ID, n)
+		}
+		byKey[key] = st
+		keys = append(keys, key)
+	}
+	return byKey, keys
+}
+
+// fieldChanges lists each field that differs, by its JSON name
+func fieldChanges(before, after models.Showtime) []string {
+	var changes []string
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		if reflect.DeepEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(b.Type().Field(i).Tag.Get("json"), ",")[0]
+		changes = append(changes, fmt.Sprintf("%s %#v -> %#v", name, b.Field(i).Interface(), a.Field(i).Interface()))
+	}
+	return changes
+}
+
+// summary identifies a showtime for a human reading the diff
+func summary(st models.Showtime) string {
+	if st.Time == "" {
+		return fmt.Sprintf("%q on %s, time unknown", st.MovieTitle, st.Date)
+	}
+	return fmt.Sprintf("%q on %s at %s", st.MovieTitle, st.Date, st.Time)
+}
+
+// testWriter sends scraper log output to the test log
+type testWriter struct {
+	t *testing.T
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
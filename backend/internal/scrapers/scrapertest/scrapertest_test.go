@@ -0,0 +1,45 @@
+package scrapertest
+
+import (
+	"reflect"
+	"testing"
+
+	"theater-showtimes/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	alien := models.Showtime{ID: "a", MovieTitle: "Alien", Date: "2026-03-04", Time: "19:00"}
+	heat := models.Showtime{ID: "h", MovieTitle: "Heat", Date: "2026-03-05", Time: "20:00"}
+	alienLate := alien
+	alienLate.Time = "21:30"
+	alienLate.Price = 12
+
+	tests := []struct {
+		name string
+		want []models.Showtime
+		got  []models.Showtime
+		diff []string
+	}{
+		{"identical", []models.Showtime{alien, heat}, []models.Showtime{heat, alien}, nil},
+		{"removed", []models.Showtime{alien, heat}, []models.Showtime{alien}, []string{
+			`- removed h: "Heat" on 2026-03-05 at 20:00`,
+		}},
+		{"added", []models.Showtime{alien}, []models.Showtime{alien, heat}, []string{
+			`+ added h: "Heat" on 2026-03-05 at 20:00`,
+		}},
+		{"changed", []models.Showtime{alien}, []models.Showtime{alienLate}, []string{
+			`~ changed a: time "19:00" -> "21:30", price 0 -> 12`,
+		}},
+		{"repeated ID", []models.Showtime{alien}, []models.Showtime{alien, alien}, []string{
+			`+ added a#2: "Alien" on 2026-03-04 at 19:00`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.want, tt.got); !reflect.DeepEqual(got, tt.diff) {
+				t.Errorf("Diff() = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
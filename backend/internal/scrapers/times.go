@@ -0,0 +1,58 @@
+package scrapers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // theaters' zone must load even where the system has no zoneinfo
+)
+
+// clockLayout is the HH:MM format showtime times are stored in
+const clockLayout = "15:04"
+
+// clockLayouts are the time formats theater websites print, tried in order
+var clockLayouts = []string{"15:04", "15:04:05", "3:04PM", "3:04 PM", "3PM", "3 PM"}
+
+// Location is the time zone theaters list their showtimes in
+var Location = mustLoadLocation("America/Los_Angeles")
+
+// mustLoadLocation loads a time zone that is known to exist
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load time zone %s: %v", name, err))
+	}
+	return loc
+}
+
+// ParseClock parses a time of day such as "19:00", "7:00 pm" or "7PM"
+// into HH:MM
+func ParseClock(text string) (string, error) {
+	cleaned := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(text, ".", "")), " "))
+	if cleaned == "" {
+		return "", fmt.Errorf("empty time")
+	}
+
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, cleaned); err == nil {
+			return t.Format(clockLayout), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized time %q", text)
+}
+
+// StartTime combines a YYYY-MM-DD date and a time of day into the moment
+// the showtime starts in the theaters' time zone
+func StartTime(date, clock string) (time.Time, error) {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+	hhmm, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, _ := time.Parse(clockLayout, hhmm)
+
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, Location), nil
+}
@@ -0,0 +1,65 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"19:00", "19:00", false},
+		{"9:05", "09:05", false},
+		{"19:00:00", "19:00", false},
+		{"7:00 pm", "19:00", false},
+		{"7:00PM", "19:00", false},
+		{"12:15 a.m.", "00:15", false},
+		{" 7 PM ", "19:00", false},
+		{"7pm", "19:00", false},
+		{"", "", true},
+		{"TBA", "", true},
+		{"25:00", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseClock(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseClock(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseClock(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStartTime(t *testing.T) {
+	tests := []struct {
+		date    string
+		clock   string
+		want    string
+		wantErr bool
+	}{
+		{"2026-07-04", "19:00", "2026-07-04T19:00:00-07:00", false},
+		{"2026-12-04", "9:30 pm", "2026-12-04T21:30:00-08:00", false},
+		{"2026-03-08", "01:30", "2026-03-08T01:30:00-08:00", false},
+		{"2026-03-08", "03:30", "2026-03-08T03:30:00-07:00", false},
+		{"2026-03-08", "", "", true},
+		{"", "19:00", "", true},
+		{"March 8", "19:00", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := StartTime(tt.date, tt.clock)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("StartTime(%q, %q) error = %v, wantErr %v", tt.date, tt.clock, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Format(time.RFC3339) != tt.want {
+			t.Errorf("StartTime(%q, %q) = %s, want %s", tt.date, tt.clock, got.Format(time.RFC3339), tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+// fingerprinter is implemented by stores that can cheaply report whether
+// their data changed on disk, typically from file modification times
+type fingerprinter interface {
+	Fingerprint() (string, error)
+}
+
+// CachedStore is a read-side cache in front of another Store. It loads
+// theaters, showtimes and movies once, indexes showtimes by date, theater,
+// TMDB ID and title, and reloads when the underlying files change on disk.
+// Writes go straight to the wrapped store and invalidate the cache.
+type CachedStore struct {
+	Store
+
+	pollInterval time.Duration
+
+	mu          sync.RWMutex
+	loaded      bool
+	fingerprint string
+	lastCheck   time.Time
+	snapshot    *snapshot
+}
+
+// snapshot is an immutable, indexed copy of the stored data
+type snapshot struct {
+	theaters  []models.Theater
+	showtimes []models.Showtime
+	movies    []models.Movie
+
+	byDate    map[string][]int
+	dates     []string // sorted keys of byDate
+	byTheater map[string][]int
+	byTMDBID  map[int][]int
+	byTitle   map[string][]int // lower-cased title
+	movieByID map[int]int
+}
+
+// NewCachedStore wraps inner with an in-memory read model. The files behind
+// inner are checked for changes at most once per pollInterval.
+func NewCachedStore(inner Store, pollInterval time.Duration) *CachedStore {
+	return &CachedStore{
+		Store:        inner,
+		pollInterval: pollInterval,
+	}
+}
+
+// LoadTheaters returns all theaters from the cache
+func (c *CachedStore) LoadTheaters() ([]models.Theater, error) {
+	snap, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Theater(nil), snap.theaters...), nil
+}
+
+// LoadShowtimes returns all showtimes from the cache
+func (c *CachedStore) LoadShowtimes() ([]models.Showtime, error) {
+	snap, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Showtime(nil), snap.showtimes...), nil
+}
+
+// QueryShowtimes answers the query from the most selective index
+func (c *CachedStore) QueryShowtimes(query ShowtimeQuery) ([]models.Showtime, error) {
+	snap, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []models.Showtime{}
+	for _, i := range snap.candidates(query) {
+		if query.Matches(snap.showtimes[i]) {
+			matched = append(matched, snap.showtimes[i])
+		}
+	}
+	return matched, nil
+}
+
+// LoadMovies returns all movies from the cache
+func (c *CachedStore) LoadMovies() ([]models.Movie, error) {
+	snap, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Movie(nil), snap.movies...), nil
+}
+
+// GetMovie returns the cached movie with the given TMDB ID, or nil if there is none
+func (c *CachedStore) GetMovie(tmdbID int) (*models.Movie, error) {
+	snap, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+
+	i, exists := snap.movieByID[tmdbID]
+	if !exists {
+		return nil, nil
+	}
+	movie := snap.movies[i]
+	return &movie, nil
+}
+
+// SaveTheaters writes through and invalidates the cache
+func (c *CachedStore) SaveTheaters(theaters []models.Theater) error {
+	defer c.Invalidate()
+	return c.Store.SaveTheaters(theaters)
+}
+
+// UpsertTheater writes through and invalidates the cache
+func (c *CachedStore) UpsertTheater(theater models.Theater) error {
+	defer c.Invalidate()
+	return c.Store.UpsertTheater(theater)
+}
+
+// SaveShowtimes writes through and invalidates the cache
+func (c *CachedStore) SaveShowtimes(showtimes []models.Showtime) error {
+	defer c.Invalidate()
+	return c.Store.SaveShowtimes(showtimes)
+}
+
+// ReplaceShowtimes writes through and invalidates the cache
+func (c *CachedStore) ReplaceShowtimes(theaterID string, dates DateRange, showtimes []models.Showtime) error {
+	defer c.Invalidate()
+	return c.Store.ReplaceShowtimes(theaterID, dates, showtimes)
+}
+
+// SaveMovies writes through and invalidates the cache
+func (c *CachedStore) SaveMovies(movies []models.Movie) error {
+	defer c.Invalidate()
+	return c.Store.SaveMovies(movies)
+}
+
+// MergeMovies writes through and invalidates the cache
+func (c *CachedStore) MergeMovies(movies []models.Movie) error {
+	defer c.Invalidate()
+	return c.Store.MergeMovies(movies)
+}
+
+// Invalidate forces the next read to reload from the wrapped store
+func (c *CachedStore) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loaded = false
+	c.snapshot = nil
+}
+
+// current returns the cached snapshot, reloading it first if it was
+// invalidated or the data on disk changed since it was built
+func (c *CachedStore) current() (*snapshot, error) {
+	c.mu.RLock()
+	if c.loaded && time.Since(c.lastCheck) < c.pollInterval {
+		snap := c.snapshot
+		c.mu.RUnlock()
+		return snap, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another reader may have refreshed the snapshot while we waited
+	if c.loaded && time.Since(c.lastCheck) < c.pollInterval {
+		return c.snapshot, nil
+	}
+
+	fingerprint, err := c.currentFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	c.lastCheck = time.Now()
+
+	if c.loaded && fingerprint == c.fingerprint {
+		return c.snapshot, nil
+	}
+
+	snap, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.snapshot = snap
+	c.fingerprint = fingerprint
+	c.loaded = true
+	return snap, nil
+}
+
+// currentFingerprint asks the wrapped store for its change fingerprint. Stores
+// that cannot report one are reloaded on every poll.
+func (c *CachedStore) currentFingerprint() (string, error) {
+	fp, ok := c.Store.(fingerprinter)
+	if !ok {
+		return time.Now().String(), nil
+	}
+	return fp.Fingerprint()
+}
+
+// load reads everything from the wrapped store and builds the indexes
+func (c *CachedStore) load() (*snapshot, error) {
+	theaters, err := c.Store.LoadTheaters()
+	if err != nil {
+		return nil, err
+	}
+	showtimes, err := c.Store.LoadShowtimes()
+	if err != nil {
+		return nil, err
+	}
+	movies, err := c.Store.LoadMovies()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &snapshot{
+		theaters:  theaters,
+		showtimes: showtimes,
+		movies:    movies,
+		byDate:    make(map[string][]int),
+		byTheater: make(map[string][]int),
+		byTMDBID:  make(map[int][]int),
+		byTitle:   make(map[string][]int),
+		movieByID: make(map[int]int, len(movies)),
+	}
+
+	for i, st := range showtimes {
+		snap.byDate[st.Date] = append(snap.byDate[st.Date], i)
+		snap.byTheater[st.TheaterID] = append(snap.byTheater[st.TheaterID], i)
+		snap.byTMDBID[st.TMDBID] = append(snap.byTMDBID[st.TMDBID], i)
+		title := strings.ToLower(st.MovieTitle)
+		snap.byTitle[title] = append(snap.byTitle[title], i)
+	}
+
+	snap.dates = make([]string, 0, len(snap.byDate))
+	for date := range snap.byDate {
+		if date != "" {
+			snap.dates = append(snap.dates, date)
+		}
+	}
+	sort.Strings(snap.dates)
+
+	for i, movie := range movies {
+		snap.movieByID[movie.TMDBID] = i
+	}
+
+	return snap, nil
+}
+
+// candidates returns the showtime positions to check for query, taken from
+// the smallest applicable index, in storage order
+func (s *snapshot) candidates(query ShowtimeQuery) []int {
+	var best []int
+	found := false
+	consider := func(positions []int) {
+		if !found || len(positions) < len(best) {
+			best = positions
+			found = true
+		}
+	}
+
+	if query.TheaterID != "" {
+		consider(s.byTheater[query.TheaterID])
+	}
+	if query.TMDBID != 0 {
+		consider(s.byTMDBID[query.TMDBID])
+	}
+	if query.MovieTitle != "" {
+		consider(s.byTitle[strings.ToLower(query.MovieTitle)])
+	}
+	if query.Dates.From != "" || query.Dates.To != "" {
+		consider(s.dateRange(query.Dates))
+	}
+
+	if found {
+		return best
+	}
+
+	all := make([]int, len(s.showtimes))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// dateRange collects the positions of showtimes dated within dates
+func (s *snapshot) dateRange(dates DateRange) []int {
+	start := 0
+	if dates.From != "" {
+		start = sort.SearchStrings(s.dates, dates.From)
+	}
+
+	var positions []int
+	for _, date := range s.dates[start:] {
+		if dates.To != "" && date > dates.To {
+			break
+		}
+		positions = append(positions, s.byDate[date]...)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// Fingerprint summarizes the modification times and sizes of the data files
+func (s *Storage) Fingerprint() (string, error) {
+	return fileFingerprint(s.dataPath, "theaters.json", "showtimes.json", "movies.json")
+}
+
+// Fingerprint summarizes the modification times and sizes of the database files
+func (s *SQLiteStore) Fingerprint() (string, error) {
+	return fileFingerprint(s.dataPath, sqliteFileName, sqliteFileName+"-wal")
+}
+
+// fileFingerprint stats each file in dir; missing files are recorded as such
+func fileFingerprint(dir string, names ...string) (string, error) {
+	var b strings.Builder
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			fmt.Fprintf(&b, "%s:missing;", name)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", name, info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String(), nil
+}
+
+var _ Store = (*CachedStore)(nil)
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"testing"
+
+	"theater-showtimes/internal/models"
+)
+
+func TestCachedStore_QueryMatchesUnderlyingStore(t *testing.T) {
+	inner := newTestStorage(t)
+	if err := inner.SaveShowtimes([]models.Showtime{
+		{ID: "a", TheaterID: "cst", MovieTitle: "Alien", TMDBID: 348, Date: "2026-02-11"},
+		{ID: "b", TheaterID: "cst", MovieTitle: "Heat", TMDBID: 949, Date: "2026-02-12"},
+		{ID: "c", TheaterID: "other", MovieTitle: "Alien", TMDBID: 348, Date: "2026-02-13"},
+		{ID: "d", TheaterID: "other", MovieTitle: "Undated"},
+	}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+	cached := NewCachedStore(inner, 0)
+
+	queries := []ShowtimeQuery{
+		{},
+		{Dates: DateRange{From: "2026-02-12"}},
+		{Dates: DateRange{To: "2026-02-12"}},
+		{TheaterID: "cst", TMDBID: 348},
+		{MovieTitle: "Alien", Dates: DateRange{From: "2026-02-12", To: "2026-02-13"}},
+		{TheaterID: "missing"},
+	}
+
+	for _, query := range queries {
+		want, err := inner.QueryShowtimes(query)
+		if err != nil {
+			t.Fatalf("inner.QueryShowtimes(%+v) error = %v", query, err)
+		}
+		got, err := cached.QueryShowtimes(query)
+		if err != nil {
+			t.Fatalf("cached.QueryShowtimes(%+v) error = %v", query, err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("QueryShowtimes(%+v) = %d showtimes, want %d", query, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].ID != want[i].ID {
+				t.Errorf("QueryShowtimes(%+v)[%d] = %s, want %s", query, i, got[i].ID, want[i].ID)
+			}
+		}
+	}
+}
+
+func TestCachedStore_ReloadsWhenFilesChange(t *testing.T) {
+	inner := newTestStorage(t)
+	cached := NewCachedStore(inner, 0)
+
+	if got, _ := cached.LoadShowtimes(); len(got) != 0 {
+		t.Fatalf("LoadShowtimes() = %d showtimes, want 0", len(got))
+	}
+
+	// A second process (e.g. the scraper CLI) writes to the same directory
+	other, err := NewStorage(inner.dataPath)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if err := other.SaveShowtimes([]models.Showtime{{ID: "a", TheaterID: "cst"}}); err != nil {
+		t.Fatalf("SaveShowtimes() error = %v", err)
+	}
+
+	got, err := cached.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("LoadShowtimes() = %d showtimes after external write, want 1", len(got))
+	}
+}
+
+func TestCachedStore_WritesInvalidate(t *testing.T) {
+	cached := NewCachedStore(newTestStorage(t), 0)
+
+	if movie, _ := cached.GetMovie(348); movie != nil {
+		t.Fatalf("GetMovie(348) = %+v before write, want nil", movie)
+	}
+
+	if err := cached.MergeMovies([]models.Movie{{TMDBID: 348, Title: "Alien"}}); err != nil {
+		t.Fatalf("MergeMovies() error = %v", err)
+	}
+
+	movie, err := cached.GetMovie(348)
+	if err != nil || movie == nil || movie.Title != "Alien" {
+		t.Errorf("GetMovie(348) = %+v, %v, want Alien", movie, err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package storage
+
+// lockPath is a no-op on platforms without file locking; only the
+// in-process mutex protects the data directory there
+func lockPath(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
+
+// syncDir is a no-op on platforms without directory fsync
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package storage
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockPath opens (creating if needed) the lock file at path and takes a
+// shared or exclusive LockFileEx lock on it, blocking until it is available
+func lockPath(path string, exclusive bool) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped)
+		file.Close()
+	}, nil
+}
+
+// syncDir is a no-op on Windows, where directories cannot be fsynced and
+// MoveFileEx already makes the rename durable
+func syncDir(dir string) error {
+	return nil
+}
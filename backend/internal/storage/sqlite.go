@@ -0,0 +1,447 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"theater-showtimes/internal/models"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteFileName is the database file created inside the data directory
+const sqliteFileName = "showtimes.db"
+
+// sqliteSchema creates the tables and indexes. Each row keeps the full record
+// as JSON in data, with the fields we query on copied into indexed columns.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS theaters (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS showtimes (
+	seq         INTEGER PRIMARY KEY AUTOINCREMENT,
+	id          TEXT NOT NULL,
+	theater_id  TEXT NOT NULL,
+	movie_title TEXT NOT NULL,
+	tmdb_id     INTEGER NOT NULL DEFAULT 0,
+	date        TEXT NOT NULL DEFAULT '',
+	data        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_showtimes_date ON showtimes (date);
+CREATE INDEX IF NOT EXISTS idx_showtimes_theater_date ON showtimes (theater_id, date);
+CREATE INDEX IF NOT EXISTS idx_showtimes_tmdb_id ON showtimes (tmdb_id);
+CREATE INDEX IF NOT EXISTS idx_showtimes_movie_title ON showtimes (movie_title);
+
+CREATE TABLE IF NOT EXISTS movies (
+	tmdb_id INTEGER PRIMARY KEY,
+	title   TEXT NOT NULL,
+	data    TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS metadata (
+	seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+	theater_id   TEXT NOT NULL,
+	last_updated TEXT NOT NULL,
+	data         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_metadata_theater ON metadata (theater_id, last_updated);
+`
+
+// SQLiteStore is the embedded SQLite Store implementation. Unlike the JSON
+// store it keeps the full scrape history and answers queries from indexes.
+type SQLiteStore struct {
+	db       *sql.DB
+	dataPath string
+}
+
+// NewSQLiteStore opens (creating if needed) the database in dataPath
+func NewSQLiteStore(dataPath string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// WAL lets the API keep reading while the scraper CLI writes; the busy
+	// timeout makes concurrent writers wait instead of failing
+	dsn := "file:" + filepath.Join(dataPath, sqliteFileName) +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db, dataPath: dataPath}, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveTheaters replaces all theaters
+func (s *SQLiteStore) SaveTheaters(theaters []models.Theater) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM theaters`); err != nil {
+			return err
+		}
+		for _, theater := range theaters {
+			if err := upsertTheater(tx, theater); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LoadTheaters returns all theaters
+func (s *SQLiteStore) LoadTheaters() ([]models.Theater, error) {
+	rows, err := s.db.Query(`SELECT data FROM theaters ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query theaters: %w", err)
+	}
+
+	var theaters []models.Theater
+	err = scanJSONRows(rows, func(data []byte) error {
+		var theater models.Theater
+		if err := json.Unmarshal(data, &theater); err != nil {
+			return err
+		}
+		theaters = append(theaters, theater)
+		return nil
+	})
+	return theaters, err
+}
+
+// UpsertTheater adds a theater or replaces the stored one with the same ID
+func (s *SQLiteStore) UpsertTheater(theater models.Theater) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return upsertTheater(tx, theater)
+	})
+}
+
+// SaveShowtimes replaces all showtimes
+func (s *SQLiteStore) SaveShowtimes(showtimes []models.Showtime) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM showtimes`); err != nil {
+			return err
+		}
+		return insertShowtimes(tx, showtimes)
+	})
+}
+
+// LoadShowtimes returns all showtimes
+func (s *SQLiteStore) LoadShowtimes() ([]models.Showtime, error) {
+	return s.QueryShowtimes(ShowtimeQuery{})
+}
+
+// ReplaceShowtimes atomically swaps one theater's showtimes within dates for
+// the given ones, leaving other theaters and dates outside the range untouched
+func (s *SQLiteStore) ReplaceShowtimes(theaterID string, dates DateRange, showtimes []models.Showtime) error {
+	for _, st := range showtimes {
+		if st.TheaterID != theaterID {
+			return fmt.Errorf("showtime %s belongs to theater %q, not %q", st.ID, st.TheaterID, theaterID)
+		}
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		query := `DELETE FROM showtimes WHERE theater_id = ?`
+		args := []interface{}{theaterID}
+		if dates.From != "" {
+			query += ` AND (date = '' OR date >= ?)`
+			args = append(args, dates.From)
+		}
+		if dates.To != "" {
+			query += ` AND (date = '' OR date <= ?)`
+			args = append(args, dates.To)
+		}
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+		return insertShowtimes(tx, showtimes)
+	})
+}
+
+// QueryShowtimes returns the showtimes matching query using the indexes
+func (s *SQLiteStore) QueryShowtimes(query ShowtimeQuery) ([]models.Showtime, error) {
+	var where []string
+	var args []interface{}
+
+	if query.Dates.From != "" {
+		where = append(where, `date <> '' AND date >= ?`)
+		args = append(args, query.Dates.From)
+	}
+	if query.Dates.To != "" {
+		where = append(where, `date <> '' AND date <= ?`)
+		args = append(args, query.Dates.To)
+	}
+	if query.TheaterID != "" {
+		where = append(where, `theater_id = ?`)
+		args = append(args, query.TheaterID)
+	}
+	if query.TMDBID != 0 {
+		where = append(where, `tmdb_id = ?`)
+		args = append(args, query.TMDBID)
+	}
+	if query.MovieTitle != "" {
+		where = append(where, `movie_title = ?`)
+		args = append(args, query.MovieTitle)
+	}
+
+	sqlQuery := `SELECT data FROM showtimes`
+	if len(where) > 0 {
+		sqlQuery += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	sqlQuery += ` ORDER BY seq`
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query showtimes: %w", err)
+	}
+
+	showtimes := []models.Showtime{}
+	err = scanJSONRows(rows, func(data []byte) error {
+		var st models.Showtime
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		showtimes = append(showtimes, st)
+		return nil
+	})
+	return showtimes, err
+}
+
+// SaveMovies replaces all movies
+func (s *SQLiteStore) SaveMovies(movies []models.Movie) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM movies`); err != nil {
+			return err
+		}
+		return upsertMovies(tx, movies)
+	})
+}
+
+// LoadMovies returns all movies
+func (s *SQLiteStore) LoadMovies() ([]models.Movie, error) {
+	rows, err := s.db.Query(`SELECT data FROM movies ORDER BY tmdb_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query movies: %w", err)
+	}
+
+	var movies []models.Movie
+	err = scanJSONRows(rows, func(data []byte) error {
+		var movie models.Movie
+		if err := json.Unmarshal(data, &movie); err != nil {
+			return err
+		}
+		movies = append(movies, movie)
+		return nil
+	})
+	return movies, err
+}
+
+// MergeMovies adds or replaces movies by TMDB ID, keeping all other stored movies
+func (s *SQLiteStore) MergeMovies(movies []models.Movie) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return upsertMovies(tx, movies)
+	})
+}
+
+// GetMovie returns the stored movie with the given TMDB ID, or nil if there is none
+func (s *SQLiteStore) GetMovie(tmdbID int) (*models.Movie, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM movies WHERE tmdb_id = ?`, tmdbID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query movie: %w", err)
+	}
+
+	var movie models.Movie
+	if err := json.Unmarshal(data, &movie); err != nil {
+		return nil, fmt.Errorf("failed to decode movie: %w", err)
+	}
+	return &movie, nil
+}
+
+// SaveMetadata appends a scrape record; SQLite keeps the full history
+func (s *SQLiteStore) SaveMetadata(metadata models.ScrapeMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO metadata (theater_id, last_updated, data) VALUES (?, ?, ?)`,
+		metadata.TheaterID, formatTimestamp(metadata.LastUpdated), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// LoadMetadata returns up to limit scrape records, newest first, for
+// theaterID or for every theater when it is empty. limit <= 0 returns all.
+func (s *SQLiteStore) LoadMetadata(theaterID string, limit int) ([]models.ScrapeMetadata, error) {
+	query := `SELECT data FROM metadata`
+	var args []interface{}
+	if theaterID != "" {
+		query += ` WHERE theater_id = ?`
+		args = append(args, theaterID)
+	}
+	query += ` ORDER BY seq DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+
+	metadata := []models.ScrapeMetadata{}
+	err = scanJSONRows(rows, func(data []byte) error {
+		var record models.ScrapeMetadata
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		metadata = append(metadata, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// GetLastUpdate returns the most recent scrape timestamp
+func (s *SQLiteStore) GetLastUpdate() (time.Time, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM metadata ORDER BY seq DESC LIMIT 1`).Scan(&data)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query metadata: %w", err)
+	}
+
+	var metadata models.ScrapeMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return metadata.LastUpdated, nil
+}
+
+// inTx runs fn inside a transaction, committing on success
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// upsertTheater writes one theater row
+func upsertTheater(tx *sql.Tx, theater models.Theater) error {
+	data, err := json.Marshal(theater)
+	if err != nil {
+		return fmt.Errorf("failed to encode theater: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO theaters (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`, theater.ID, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save theater %s: %w", theater.ID, err)
+	}
+	return nil
+}
+
+// insertShowtimes writes showtime rows
+func insertShowtimes(tx *sql.Tx, showtimes []models.Showtime) error {
+	stmt, err := tx.Prepare(`INSERT INTO showtimes (id, theater_id, movie_title, tmdb_id, date, data)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, st := range showtimes {
+		data, err := json.Marshal(st)
+		if err != nil {
+			return fmt.Errorf("failed to encode showtime: %w", err)
+		}
+		if _, err := stmt.Exec(st.ID, st.TheaterID, st.MovieTitle, st.TMDBID, st.Date, string(data)); err != nil {
+			return fmt.Errorf("failed to save showtime %s: %w", st.ID, err)
+		}
+	}
+	return nil
+}
+
+// upsertMovies writes movie rows keyed by TMDB ID
+func upsertMovies(tx *sql.Tx, movies []models.Movie) error {
+	stmt, err := tx.Prepare(`INSERT INTO movies (tmdb_id, title, data) VALUES (?, ?, ?)
+		ON CONFLICT(tmdb_id) DO UPDATE SET title = excluded.title, data = excluded.data`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, movie := range movies {
+		data, err := json.Marshal(movie)
+		if err != nil {
+			return fmt.Errorf("failed to encode movie: %w", err)
+		}
+		if _, err := stmt.Exec(movie.TMDBID, movie.Title, string(data)); err != nil {
+			return fmt.Errorf("failed to save movie %d: %w", movie.TMDBID, err)
+		}
+	}
+	return nil
+}
+
+// scanJSONRows calls fn with the data column of each row and closes rows
+func scanJSONRows(rows *sql.Rows, fn func(data []byte) error) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("failed to decode row: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// timestampLayout is a fixed-width UTC layout so stored times sort as text
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// formatTimestamp stores times in a sortable UTC form
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+var _ Store = (*SQLiteStore)(nil)
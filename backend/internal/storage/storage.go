@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	"theater-showtimes/internal/models"
 )
 
-// Storage handles JSON file-based data persistence
+// lockFileName is the file used to serialize access across processes
+// (the API server and the scraper CLI share the data directory)
+const lockFileName = ".lock"
+
+// Storage is the JSON file-based Store implementation
 type Storage struct {
 	dataPath string
 	mu       sync.RWMutex
@@ -29,10 +34,47 @@ func NewStorage(dataPath string) (*Storage, error) {
 	}, nil
 }
 
+// lock takes the exclusive in-process and cross-process lock; the returned
+// func releases both
+func (s *Storage) lock() (func(), error) {
+	s.mu.Lock()
+
+	unlockFile, err := lockPath(filepath.Join(s.dataPath, lockFileName), true)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock data directory: %w", err)
+	}
+
+	return func() {
+		unlockFile()
+		s.mu.Unlock()
+	}, nil
+}
+
+// rlock takes the shared in-process and cross-process lock; the returned
+// func releases both
+func (s *Storage) rlock() (func(), error) {
+	s.mu.RLock()
+
+	unlockFile, err := lockPath(filepath.Join(s.dataPath, lockFileName), false)
+	if err != nil {
+		s.mu.RUnlock()
+		return nil, fmt.Errorf("failed to lock data directory: %w", err)
+	}
+
+	return func() {
+		unlockFile()
+		s.mu.RUnlock()
+	}, nil
+}
+
 // SaveTheaters saves theaters to JSON
 func (s *Storage) SaveTheaters(theaters []models.Theater) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "theaters.json")
 	return s.writeJSON(path, theaters)
@@ -40,39 +82,151 @@ func (s *Storage) SaveTheaters(theaters []models.Theater) error {
 
 // LoadTheaters loads theaters from JSON
 func (s *Storage) LoadTheaters() ([]models.Theater, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	unlock, err := s.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "theaters.json")
 	var theaters []models.Theater
-	err := s.readJSON(path, &theaters)
+	err = s.readJSON(path, &theaters)
 	return theaters, err
 }
 
+// UpsertTheater adds a theater or replaces the stored one with the same ID
+func (s *Storage) UpsertTheater(theater models.Theater) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := filepath.Join(s.dataPath, "theaters.json")
+	var theaters []models.Theater
+	if err := s.readJSON(path, &theaters); err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range theaters {
+		if theaters[i].ID == theater.ID {
+			theaters[i] = theater
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		theaters = append(theaters, theater)
+	}
+
+	return s.writeJSON(path, theaters)
+}
+
 // SaveShowtimes saves showtimes to JSON
 func (s *Storage) SaveShowtimes(showtimes []models.Showtime) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "showtimes.json")
 	return s.writeJSON(path, showtimes)
 }
 
+// DateRange bounds showtime dates in YYYY-MM-DD form, inclusive. An empty
+// bound leaves that side of the range open.
+type DateRange struct {
+	From string
+	To   string
+}
+
+// Contains reports whether date falls inside the range. Showtimes without a
+// date are treated as inside so stale undated entries get replaced too.
+func (r DateRange) Contains(date string) bool {
+	if date == "" {
+		return true
+	}
+	if r.From != "" && date < r.From {
+		return false
+	}
+	if r.To != "" && date > r.To {
+		return false
+	}
+	return true
+}
+
+// ReplaceShowtimes atomically swaps one theater's showtimes within dates for
+// the given ones, leaving other theaters and dates outside the range untouched
+func (s *Storage) ReplaceShowtimes(theaterID string, dates DateRange, showtimes []models.Showtime) error {
+	for _, st := range showtimes {
+		if st.TheaterID != theaterID {
+			return fmt.Errorf("showtime %s belongs to theater %q, not %q", st.ID, st.TheaterID, theaterID)
+		}
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := filepath.Join(s.dataPath, "showtimes.json")
+	var existing []models.Showtime
+	if err := s.readJSON(path, &existing); err != nil {
+		return err
+	}
+
+	merged := make([]models.Showtime, 0, len(existing)+len(showtimes))
+	for _, st := range existing {
+		if st.TheaterID == theaterID && dates.Contains(st.Date) {
+			continue
+		}
+		merged = append(merged, st)
+	}
+	merged = append(merged, showtimes...)
+
+	return s.writeJSON(path, merged)
+}
+
 // LoadShowtimes loads showtimes from JSON
 func (s *Storage) LoadShowtimes() ([]models.Showtime, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	unlock, err := s.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "showtimes.json")
 	var showtimes []models.Showtime
-	err := s.readJSON(path, &showtimes)
+	err = s.readJSON(path, &showtimes)
 	return showtimes, err
 }
 
+// QueryShowtimes returns the showtimes matching query
+func (s *Storage) QueryShowtimes(query ShowtimeQuery) ([]models.Showtime, error) {
+	showtimes, err := s.LoadShowtimes()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []models.Showtime{}
+	for _, st := range showtimes {
+		if query.Matches(st) {
+			matched = append(matched, st)
+		}
+	}
+	return matched, nil
+}
+
 // SaveMovies saves movies to JSON
 func (s *Storage) SaveMovies(movies []models.Movie) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "movies.json")
 	return s.writeJSON(path, movies)
@@ -80,11 +234,65 @@ func (s *Storage) SaveMovies(movies []models.Movie) error {
 
 // LoadMovies loads movies from JSON
 func (s *Storage) LoadMovies() ([]models.Movie, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	unlock, err := s.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.loadMovies()
+}
+
+// MergeMovies adds or replaces movies by TMDB ID, keeping all other stored movies
+func (s *Storage) MergeMovies(movies []models.Movie) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	existing, err := s.loadMovies()
+	if err != nil {
+		return err
+	}
+
+	index := make(map[int]int, len(existing))
+	for i, movie := range existing {
+		index[movie.TMDBID] = i
+	}
+
+	for _, movie := range movies {
+		if i, exists := index[movie.TMDBID]; exists {
+			existing[i] = movie
+			continue
+		}
+		index[movie.TMDBID] = len(existing)
+		existing = append(existing, movie)
+	}
 
 	path := filepath.Join(s.dataPath, "movies.json")
-	
+	return s.writeJSON(path, existing)
+}
+
+// GetMovie returns the stored movie with the given TMDB ID, or nil if there is none
+func (s *Storage) GetMovie(tmdbID int) (*models.Movie, error) {
+	movies, err := s.LoadMovies()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range movies {
+		if movies[i].TMDBID == tmdbID {
+			return &movies[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// loadMovies reads movies.json in either of its formats; callers hold the lock
+func (s *Storage) loadMovies() ([]models.Movie, error) {
+	path := filepath.Join(s.dataPath, "movies.json")
+
 	// Try loading as map first (saved by scraper)
 	var moviesMap map[string]*models.Movie
 	if err := s.readJSON(path, &moviesMap); err == nil {
@@ -95,9 +303,13 @@ func (s *Storage) LoadMovies() ([]models.Movie, error) {
 				movies = append(movies, *movie)
 			}
 		}
+		// Map iteration order is random; keep reads stable
+		sort.Slice(movies, func(i, j int) bool {
+			return movies[i].TMDBID < movies[j].TMDBID
+		})
 		return movies, nil
 	}
-	
+
 	// Fallback to array format
 	var movies []models.Movie
 	err := s.readJSON(path, &movies)
@@ -106,8 +318,11 @@ func (s *Storage) LoadMovies() ([]models.Movie, error) {
 
 // SaveMetadata saves scrape metadata
 func (s *Storage) SaveMetadata(metadata models.ScrapeMetadata) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Load existing metadata
 	path := filepath.Join(s.dataPath, "metadata.json")
@@ -125,10 +340,40 @@ func (s *Storage) SaveMetadata(metadata models.ScrapeMetadata) error {
 	return s.writeJSON(path, allMetadata)
 }
 
+// LoadMetadata returns up to limit scrape records, newest first, for
+// theaterID or for every theater when it is empty. limit <= 0 returns all.
+func (s *Storage) LoadMetadata(theaterID string, limit int) ([]models.ScrapeMetadata, error) {
+	unlock, err := s.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	path := filepath.Join(s.dataPath, "metadata.json")
+	var allMetadata []models.ScrapeMetadata
+	if err := s.readJSON(path, &allMetadata); err != nil {
+		return nil, err
+	}
+
+	matched := []models.ScrapeMetadata{}
+	for i := len(allMetadata) - 1; i >= 0; i-- {
+		if limit > 0 && len(matched) == limit {
+			break
+		}
+		if theaterID == "" || allMetadata[i].TheaterID == theaterID {
+			matched = append(matched, allMetadata[i])
+		}
+	}
+	return matched, nil
+}
+
 // GetLastUpdate returns the most recent scrape timestamp
 func (s *Storage) GetLastUpdate() (time.Time, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	unlock, err := s.rlock()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer unlock()
 
 	path := filepath.Join(s.dataPath, "metadata.json")
 	var allMetadata []models.ScrapeMetadata
@@ -144,21 +389,45 @@ func (s *Storage) GetLastUpdate() (time.Time, error) {
 	return allMetadata[len(allMetadata)-1].LastUpdated, nil
 }
 
-// Helper function to write JSON to file
+// Close is a no-op; the JSON store holds no open resources between calls
+func (s *Storage) Close() error {
+	return nil
+}
+
+// writeJSON encodes data to a temp file in the same directory, fsyncs it and
+// renames it over path, so readers only ever see the old or the new file
 func (s *Storage) writeJSON(path string, data interface{}) error {
-	file, err := os.Create(path)
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
-	defer file.Close()
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename succeeded
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(tmp)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(data); err != nil {
+		tmp.Close()
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 
-	return nil
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	return syncDir(dir)
 }
 
 // Helper function to read JSON from file
@@ -179,3 +448,5 @@ func (s *Storage) readJSON(path string, data interface{}) error {
 
 	return nil
 }
+
+var _ Store = (*Storage)(nil)
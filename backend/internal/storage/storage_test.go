@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	store, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	return store
+}
+
+// forEachBackend runs fn against a fresh store of every backend
+func forEachBackend(t *testing.T, fn func(t *testing.T, store Store)) {
+	t.Helper()
+
+	for _, backend := range []string{BackendJSON, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			if err != nil {
+				t.Fatalf("Open(%q) error = %v", backend, err)
+			}
+			t.Cleanup(func() { store.Close() })
+
+			fn(t, store)
+		})
+	}
+}
+
+func storedIDs(t *testing.T, store Store) []string {
+	t.Helper()
+
+	showtimes, err := store.LoadShowtimes()
+	if err != nil {
+		t.Fatalf("LoadShowtimes() error = %v", err)
+	}
+	ids := make([]string, 0, len(showtimes))
+	for _, st := range showtimes {
+		ids = append(ids, st.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestReplaceShowtimes(t *testing.T) {
+	existing := []models.Showtime{
+		{ID: "cst-jan", TheaterID: "cst", Date: "2026-01-30"},
+		{ID: "cst-feb", TheaterID: "cst", Date: "2026-02-11"},
+		{ID: "cst-undated", TheaterID: "cst"},
+		{ID: "other-feb", TheaterID: "other", Date: "2026-02-11"},
+	}
+	replacement := []models.Showtime{
+		{ID: "cst-new", TheaterID: "cst", Date: "2026-02-12"},
+	}
+
+	tests := []struct {
+		name  string
+		dates DateRange
+		want  []string
+	}{
+		{
+			name:  "open range replaces the whole theater",
+			dates: DateRange{},
+			want:  []string{"cst-new", "other-feb"},
+		},
+		{
+			name:  "bounded range keeps dates outside it",
+			dates: DateRange{From: "2026-02-01", To: "2026-02-28"},
+			want:  []string{"cst-jan", "cst-new", "other-feb"},
+		},
+		{
+			name:  "open-ended range from a date",
+			dates: DateRange{From: "2026-02-12"},
+			want:  []string{"cst-feb", "cst-jan", "cst-new", "other-feb"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, store Store) {
+				if err := store.SaveShowtimes(existing); err != nil {
+					t.Fatalf("SaveShowtimes() error = %v", err)
+				}
+
+				if err := store.ReplaceShowtimes("cst", tt.dates, replacement); err != nil {
+					t.Fatalf("ReplaceShowtimes() error = %v", err)
+				}
+
+				got := storedIDs(t, store)
+				if len(got) != len(tt.want) {
+					t.Fatalf("stored IDs = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("stored IDs = %v, want %v", got, tt.want)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestReplaceShowtimes_RejectsOtherTheaters(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store) {
+		err := store.ReplaceShowtimes("cst", DateRange{}, []models.Showtime{
+			{ID: "other-1", TheaterID: "other"},
+		})
+		if err == nil {
+			t.Fatal("ReplaceShowtimes() error = nil, want mismatch error")
+		}
+	})
+}
+
+func TestQueryShowtimes(t *testing.T) {
+	showtimes := []models.Showtime{
+		{ID: "a", TheaterID: "cst", MovieTitle: "Alien", TMDBID: 348, Date: "2026-02-11"},
+		{ID: "b", TheaterID: "cst", MovieTitle: "Heat", TMDBID: 949, Date: "2026-02-12"},
+		{ID: "c", TheaterID: "other", MovieTitle: "Alien", TMDBID: 348, Date: "2026-02-13"},
+		{ID: "d", TheaterID: "other", MovieTitle: "Undated"},
+	}
+
+	tests := []struct {
+		name  string
+		query ShowtimeQuery
+		want  []string
+	}{
+		{"empty query returns everything", ShowtimeQuery{}, []string{"a", "b", "c", "d"}},
+		{"single date", ShowtimeQuery{Dates: DateRange{From: "2026-02-12", To: "2026-02-12"}}, []string{"b"}},
+		{"date range skips undated", ShowtimeQuery{Dates: DateRange{From: "2026-02-12"}}, []string{"b", "c"}},
+		{"theater", ShowtimeQuery{TheaterID: "other"}, []string{"c", "d"}},
+		{"tmdb id", ShowtimeQuery{TMDBID: 348}, []string{"a", "c"}},
+		{"title and theater", ShowtimeQuery{MovieTitle: "Alien", TheaterID: "cst"}, []string{"a"}},
+	}
+
+	forEachBackend(t, func(t *testing.T, store Store) {
+		if err := store.SaveShowtimes(showtimes); err != nil {
+			t.Fatalf("SaveShowtimes() error = %v", err)
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := store.QueryShowtimes(tt.query)
+				if err != nil {
+					t.Fatalf("QueryShowtimes() error = %v", err)
+				}
+				ids := make([]string, 0, len(got))
+				for _, st := range got {
+					ids = append(ids, st.ID)
+				}
+				sort.Strings(ids)
+				if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("QueryShowtimes() IDs = %v, want %v", ids, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestMergeMovies_ReplacesByTMDBID(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store) {
+		if err := store.SaveMovies([]models.Movie{
+			{TMDBID: 1, Title: "Old Title"},
+			{TMDBID: 2, Title: "Kept"},
+		}); err != nil {
+			t.Fatalf("SaveMovies() error = %v", err)
+		}
+
+		if err := store.MergeMovies([]models.Movie{
+			{TMDBID: 1, Title: "New Title"},
+			{TMDBID: 3, Title: "Added"},
+		}); err != nil {
+			t.Fatalf("MergeMovies() error = %v", err)
+		}
+
+		movies, err := store.LoadMovies()
+		if err != nil {
+			t.Fatalf("LoadMovies() error = %v", err)
+		}
+		titles := map[int]string{}
+		for _, movie := range movies {
+			titles[movie.TMDBID] = movie.Title
+		}
+		if len(titles) != 3 || titles[1] != "New Title" || titles[2] != "Kept" || titles[3] != "Added" {
+			t.Errorf("movies = %v, want merged set", titles)
+		}
+
+		movie, err := store.GetMovie(3)
+		if err != nil || movie == nil || movie.Title != "Added" {
+			t.Errorf("GetMovie(3) = %+v, %v, want Added", movie, err)
+		}
+		if movie, err := store.GetMovie(99); err != nil || movie != nil {
+			t.Errorf("GetMovie(99) = %+v, %v, want nil, nil", movie, err)
+		}
+	})
+}
+
+func TestLoadMetadata_NewestFirstPerTheater(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store) {
+		base := time.Date(2026, 2, 11, 12, 0, 0, 0, time.UTC)
+		for i, theaterID := range []string{"cst", "other", "cst", "cst"} {
+			err := store.SaveMetadata(models.ScrapeMetadata{TheaterID: theaterID, LastUpdated: base.Add(time.Duration(i) * time.Hour)})
+			if err != nil {
+				t.Fatalf("SaveMetadata() error = %v", err)
+			}
+		}
+
+		tests := []struct {
+			theaterID string
+			limit     int
+			want      []int // hours after base
+		}{
+			{"", 0, []int{3, 2, 1, 0}},
+			{"cst", 2, []int{3, 2}},
+			{"other", 5, []int{1}},
+			{"missing", 1, []int{}},
+		}
+
+		for _, tt := range tests {
+			got, err := store.LoadMetadata(tt.theaterID, tt.limit)
+			if err != nil {
+				t.Fatalf("LoadMetadata(%q, %d) error = %v", tt.theaterID, tt.limit, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadMetadata(%q, %d) = %d records, want %d", tt.theaterID, tt.limit, len(got), len(tt.want))
+			}
+			for i, hours := range tt.want {
+				if want := base.Add(time.Duration(hours) * time.Hour); !got[i].LastUpdated.Equal(want) {
+					t.Errorf("LoadMetadata(%q, %d)[%d] = %v, want %v", tt.theaterID, tt.limit, i, got[i].LastUpdated, want)
+				}
+			}
+		}
+	})
+}
+
+func TestWriteJSON_ConcurrentReadersNeverSeePartialFiles(t *testing.T) {
+	store := newTestStorage(t)
+
+	showtimes := make([]models.Showtime, 500)
+	for i := range showtimes {
+		showtimes[i] = models.Showtime{ID: fmt.Sprintf("st-%d", i), TheaterID: "cst", MovieTitle: "A Long Movie Title"}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if err := store.SaveShowtimes(showtimes); err != nil {
+					t.Errorf("SaveShowtimes() error = %v", err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				got, err := store.LoadShowtimes()
+				if err != nil {
+					t.Errorf("LoadShowtimes() error = %v", err)
+					return
+				}
+				if len(got) != 0 && len(got) != len(showtimes) {
+					t.Errorf("LoadShowtimes() returned %d showtimes, want 0 or %d", len(got), len(showtimes))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := os.ReadDir(store.dataPath)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temp file %s left behind", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+// Store is the persistence interface shared by every storage backend
+type Store interface {
+	// Theaters
+	SaveTheaters(theaters []models.Theater) error
+	LoadTheaters() ([]models.Theater, error)
+	UpsertTheater(theater models.Theater) error
+
+	// Showtimes
+	SaveShowtimes(showtimes []models.Showtime) error
+	LoadShowtimes() ([]models.Showtime, error)
+	ReplaceShowtimes(theaterID string, dates DateRange, showtimes []models.Showtime) error
+	QueryShowtimes(query ShowtimeQuery) ([]models.Showtime, error)
+
+	// Movies
+	SaveMovies(movies []models.Movie) error
+	LoadMovies() ([]models.Movie, error)
+	MergeMovies(movies []models.Movie) error
+	GetMovie(tmdbID int) (*models.Movie, error)
+
+	// Scrape metadata
+	SaveMetadata(metadata models.ScrapeMetadata) error
+	LoadMetadata(theaterID string, limit int) ([]models.ScrapeMetadata, error)
+	GetLastUpdate() (time.Time, error)
+
+	// Close releases any resources held by the backend
+	Close() error
+}
+
+// ShowtimeQuery selects showtimes by indexed fields. Zero-valued fields do not filter.
+type ShowtimeQuery struct {
+	Dates      DateRange
+	TheaterID  string
+	TMDBID     int
+	MovieTitle string
+}
+
+// Matches reports whether a showtime satisfies the query
+func (q ShowtimeQuery) Matches(st models.Showtime) bool {
+	if (q.Dates.From != "" || q.Dates.To != "") && (st.Date == "" || !q.Dates.Contains(st.Date)) {
+		return false
+	}
+	if q.TheaterID != "" && st.TheaterID != q.TheaterID {
+		return false
+	}
+	if q.TMDBID != 0 && st.TMDBID != q.TMDBID {
+		return false
+	}
+	if q.MovieTitle != "" && st.MovieTitle != q.MovieTitle {
+		return false
+	}
+	return true
+}
+
+// Supported storage backends
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Open creates the store for the named backend rooted at dataPath
+func Open(backend, dataPath string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		return NewStorage(dataPath)
+	case BackendSQLite:
+		return NewSQLiteStore(dataPath)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (want %q or %q)", backend, BackendJSON, BackendSQLite)
+	}
+}
@@ -2,44 +2,152 @@ package tmdb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
 	"theater-showtimes/internal/models"
 )
 
+// ErrDisabled is returned by lookups when the client has no credentials
+var ErrDisabled = errors.New("TMDB is disabled: no API key or access token configured")
+
+// ErrNotFound is returned when TMDB has no movie with the requested ID
+var ErrNotFound = errors.New("movie not found on TMDB")
+
+// redacted replaces credentials in errors and logs
+const redacted = "REDACTED"
+
+// Config holds the client's credentials and cache settings
+type Config struct {
+	// APIKey is a TMDB v3 API key, sent as the api_key query parameter
+	APIKey string
+
+	// AccessToken is a TMDB v4 read access token, sent as a bearer token.
+	// It is used instead of APIKey when both are set.
+	AccessToken string
+
+	CacheTTL time.Duration
+
+	// BaseURL replaces TMDB's API address, for tests
+	BaseURL string
+}
+
 // Client handles TMDB API communication
 type Client struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
-	cache      *Cache
+	apiKey      string
+	accessToken string
+	baseURL     string
+	httpClient  *http.Client
+	cache       *Cache
 }
 
-// NewClient creates a new TMDB client
-func NewClient(cacheTTL time.Duration) *Client {
-	apiKey := os.Getenv("TMDB_API_KEY")
-	if apiKey == "" {
-		apiKey = "4574f5c4ac74dc981d3e6a6491ebf58d" // Default from config
-	}
-
-	return &Client{
-		apiKey:  apiKey,
-		baseURL: "https://api.themoviedb.org/3",
+// NewClient creates a new TMDB client. Without an API key or access token
+// the client is disabled: lookups return ErrDisabled and EnrichShowtimes
+// leaves showtimes as they are.
+func NewClient(cfg Config) *Client {
+	client := &Client{
+		accessToken: cfg.AccessToken,
+		baseURL:     "https://api.themoviedb.org/3",
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		cache: NewCache(cacheTTL),
+		cache: NewCache(cfg.CacheTTL),
+	}
+	if cfg.AccessToken == "" {
+		client.apiKey = cfg.APIKey
+	}
+	if cfg.BaseURL != "" {
+		client.baseURL = cfg.BaseURL
+	}
+	return client
+}
+
+// Enabled reports whether the client has credentials to query TMDB with.
+// A nil client is disabled.
+func (c *Client) Enabled() bool {
+	return c != nil && (c.apiKey != "" || c.accessToken != "")
+}
+
+// AuthMethod describes how the client authenticates: "access_token",
+// "api_key" or "disabled"
+func (c *Client) AuthMethod() string {
+	switch {
+	case !c.Enabled():
+		return "disabled"
+	case c.accessToken != "":
+		return "access_token"
+	default:
+		return "api_key"
+	}
+}
+
+// get requests path with params, adding the client's credentials. Errors
+// never contain the credentials.
+func (c *Client) get(path string, params url.Values) (*http.Response, error) {
+	if !c.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	if c.apiKey != "" {
+		query.Set("api_key", c.apiKey)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %s: %s", path, c.redact(err.Error()))
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			// The request URL carries the API key
+			urlErr.URL = c.redact(urlErr.URL)
+		}
+		if message := c.redact(err.Error()); message != err.Error() {
+			return nil, errors.New(message)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// statusError describes a failed response without echoing credentials
+func (c *Client) statusError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s failed with status %d: %s", action, resp.StatusCode, c.redact(strings.TrimSpace(string(body))))
+}
+
+// redact replaces the client's credentials in s
+func (c *Client) redact(s string) string {
+	for _, secret := range []string{c.apiKey, c.accessToken} {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+			s = strings.ReplaceAll(s, url.QueryEscape(secret), redacted)
+		}
+	}
+	return s
 }
 
 // SearchMovie searches for a movie by title
 func (c *Client) SearchMovie(title string) (*models.Movie, error) {
+	if !c.Enabled() {
+		return nil, ErrDisabled
+	}
+
 	// Check cache first
 	if cached := c.cache.Get(title); cached != nil {
 		return cached, nil
@@ -71,25 +179,21 @@ func (c *Client) normalizeTitle(title string) string {
 
 // searchTMDB performs the actual TMDB search
 func (c *Client) searchTMDB(title string) (*models.Movie, error) {
-	// Build request URL
+	// Build request
 	params := url.Values{}
-	params.Add("api_key", c.apiKey)
 	params.Add("query", title)
 	params.Add("language", "en-US")
 	params.Add("page", "1")
-	
-	requestURL := fmt.Sprintf("%s/search/movie?%s", c.baseURL, params.Encode())
 
 	// Make request
-	resp, err := c.httpClient.Get(requestURL)
+	resp, err := c.get("/search/movie", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search TMDB: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB search failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, c.statusError("TMDB search", resp)
 	}
 
 	// Parse response
@@ -159,17 +263,18 @@ func (c *Client) buildBackdropURL(backdropPath string) string {
 // enrichMovieDetails fetches additional details like runtime, rating, genres, cast
 func (c *Client) enrichMovieDetails(movie *models.Movie) error {
 	params := url.Values{}
-	params.Add("api_key", c.apiKey)
 	params.Add("append_to_response", "credits,release_dates")
-	
-	requestURL := fmt.Sprintf("%s/movie/%d?%s", c.baseURL, movie.TMDBID, params.Encode())
 
-	resp, err := c.httpClient.Get(requestURL)
+	resp, err := c.get(fmt.Sprintf("/movie/%d", movie.TMDBID), params)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return c.statusError("TMDB movie details", resp)
+	}
+
 	var details struct {
 		Runtime int      `json:"runtime"`
 		Genres  []struct {
@@ -242,28 +347,31 @@ func (c *Client) enrichMovieDetails(movie *models.Movie) error {
 
 // GetMovieDetails fetches detailed information for a movie by TMDB ID
 func (c *Client) GetMovieDetails(tmdbID int) (*models.Movie, error) {
+	if !c.Enabled() {
+		return nil, ErrDisabled
+	}
+
 	cacheKey := fmt.Sprintf("id-%d", tmdbID)
-	
+
 	// Check cache
 	if cached := c.cache.Get(cacheKey); cached != nil {
 		return cached, nil
 	}
 
 	params := url.Values{}
-	params.Add("api_key", c.apiKey)
 	params.Add("append_to_response", "credits,release_dates")
-	
-	requestURL := fmt.Sprintf("%s/movie/%d?%s", c.baseURL, tmdbID, params.Encode())
 
-	resp, err := c.httpClient.Get(requestURL)
+	resp, err := c.get(fmt.Sprintf("/movie/%d", tmdbID), params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie details: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("movie %d: %w", tmdbID, ErrNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB get movie failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, c.statusError("TMDB get movie", resp)
 	}
 
 	var details struct {
@@ -360,8 +468,13 @@ func (c *Client) GetMovieDetails(tmdbID int) (*models.Movie, error) {
 	return movie, nil
 }
 
-// EnrichShowtimes takes a slice of showtimes and enriches each with TMDB data
+// EnrichShowtimes takes a slice of showtimes and enriches each with TMDB data.
+// A disabled client returns the showtimes unchanged and no movies.
 func (c *Client) EnrichShowtimes(showtimes []models.Showtime) ([]models.Showtime, map[string]*models.Movie) {
+	if !c.Enabled() {
+		return showtimes, nil
+	}
+
 	enriched := make([]models.Showtime, 0, len(showtimes))
 	movieCache := make(map[string]*models.Movie)
 
@@ -0,0 +1,105 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"theater-showtimes/internal/models"
+)
+
+// newTestClient returns a client with cfg that sends requests to handler
+func newTestClient(t *testing.T, cfg Config, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.CacheTTL = time.Hour
+	client := NewClient(cfg)
+	client.baseURL = server.URL
+	return client
+}
+
+func TestClient_Authentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantAuth   string
+		wantAPIKey string
+		wantMethod string
+	}{
+		{"api key", Config{APIKey: "v3-key"}, "", "v3-key", "api_key"},
+		{"access token", Config{AccessToken: "v4-token"}, "Bearer v4-token", "", "access_token"},
+		{"token preferred", Config{APIKey: "v3-key", AccessToken: "v4-token"}, "Bearer v4-token", "", "access_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAuth, gotAPIKey string
+			client := newTestClient(t, tt.cfg, func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				gotAPIKey = r.URL.Query().Get("api_key")
+				fmt.Fprint(w, `{"id": 348, "title": "Alien"}`)
+			})
+
+			if got := client.AuthMethod(); got != tt.wantMethod {
+				t.Errorf("AuthMethod() = %q, want %q", got, tt.wantMethod)
+			}
+			if _, err := client.GetMovieDetails(348); err != nil {
+				t.Fatalf("GetMovieDetails() error = %v", err)
+			}
+			if gotAuth != tt.wantAuth || gotAPIKey != tt.wantAPIKey {
+				t.Errorf("request sent Authorization %q and api_key %q, want %q and %q", gotAuth, gotAPIKey, tt.wantAuth, tt.wantAPIKey)
+			}
+		})
+	}
+}
+
+func TestClient_RedactsCredentials(t *testing.T) {
+	const key = "secret-key"
+
+	// TMDB echoes the request in some error bodies
+	client := newTestClient(t, Config{APIKey: key}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"status_message": "Invalid API key: %s", "request": %q}`, key, r.URL.String())
+	})
+	_, err := client.SearchMovie("Alien")
+	if err == nil || strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), "401") {
+		t.Errorf("SearchMovie() error = %v, want a 401 without the key", err)
+	}
+
+	// Transport errors include the request URL
+	unreachable := NewClient(Config{APIKey: key, CacheTTL: time.Hour})
+	unreachable.baseURL = "http://127.0.0.1:1"
+	_, err = unreachable.GetMovieDetails(348)
+	if err == nil || strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), redacted) {
+		t.Errorf("GetMovieDetails() error = %v, want the key redacted", err)
+	}
+}
+
+func TestClient_Disabled(t *testing.T) {
+	client := newTestClient(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("disabled client requested %s", r.URL.Path)
+	})
+
+	if client.Enabled() || client.AuthMethod() != "disabled" {
+		t.Errorf("Enabled() = %v, AuthMethod() = %q, want a disabled client", client.Enabled(), client.AuthMethod())
+	}
+	if _, err := client.SearchMovie("Alien"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("SearchMovie() error = %v, want ErrDisabled", err)
+	}
+	if _, err := client.GetMovieDetails(348); !errors.Is(err, ErrDisabled) {
+		t.Errorf("GetMovieDetails() error = %v, want ErrDisabled", err)
+	}
+
+	showtimes := []models.Showtime{{ID: "1", MovieTitle: "Alien"}}
+	enriched, movies := client.EnrichShowtimes(showtimes)
+	if len(enriched) != 1 || enriched[0].TMDBID != 0 || len(movies) != 0 {
+		t.Errorf("EnrichShowtimes() = %+v, %v, want the showtimes unchanged and no movies", enriched, movies)
+	}
+}
@@ -55,7 +55,7 @@ function MovieCard({ movie, showtimes, theaters }) {
                 <div className="showtimes-preview">
                     {movieShowtimes.slice(0, 3).map((st, idx) => (
                         <span key={idx} className="showtime-tag">
-                            {formatTimeToPacific(st.time)}
+                            {st.time_unknown ? 'Time TBA' : formatTimeToPacific(st.time)}
                         </span>
                     ))}
                     {movieShowtimes.length > 3 && <span className="more">+{movieShowtimes.length - 3} more</span>}
@@ -38,12 +38,40 @@ export const getMovieDetails = async (movieId) => {
 }
 
 // Scraper
+// Starts a background scrape job; poll it with getScrapeJob(job.id)
 export const triggerScrape = async (theaterIds = []) => {
     const response = await api.post('/scrape', { theater_ids: theaterIds })
     return response.data
 }
 
+export const getScrapeJob = async (jobId) => {
+    const response = await api.get(`/scrape/jobs/${jobId}`)
+    return response.data
+}
+
+export const cancelScrapeJob = async (jobId) => {
+    const response = await api.delete(`/scrape/jobs/${jobId}`)
+    return response.data
+}
+
+// Opens a Server-Sent Events stream of scrape progress, optionally for one job.
+// Listen for named events (e.g. 'theater_finished'); call close() when done.
+export const subscribeScrapeEvents = (jobId) => {
+    const query = jobId ? `?job_id=${encodeURIComponent(jobId)}` : ''
+    return new EventSource(`${API_BASE_URL}/scrape/events${query}`)
+}
+
+export const getScrapeHistory = async (params = {}) => {
+    const response = await api.get('/scrape/history', { params })
+    return response.data
+}
+
 // Health & Meta
+export const getScrapersHealth = async () => {
+    const response = await api.get('/scrapers/health')
+    return response.data
+}
+
 export const getHealth = async () => {
     const response = await api.get('/health')
     return response.data
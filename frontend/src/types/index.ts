@@ -35,6 +35,9 @@ export interface Showtime {
     tmdb_id: number
     date: string
     time: string
+    starts_at?: string // RFC 3339 with the theater's UTC offset; absent when the time is unknown
+    ends_at?: string
+    time_unknown?: boolean
     format: string
     price?: number
     booking_url?: string